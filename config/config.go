@@ -1,7 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -16,8 +20,84 @@ type AppConfig struct {
 	RedisPassword string
 	JWTSecret     string
 	DBSSLMode     string
+	AppURL        string
+	MailDriver    string
+	MailFrom      string
+	MailOutputDir string
+	AuthMode      string
+	JWTKeyID      string
+	JWTAlgorithm  string
+	JWTKeysDir    string
+	// JWTKeyRotationInterval is how often a new signing key pair is generated, zero disables rotation
+	JWTKeyRotationInterval  time.Duration
+	EmailVerificationPolicy string
+	RateLimits              map[string]RateLimitPolicy
+	// DeleteReferencePolicy is what happens to transactions of a deleted category or budget when
+	// the request does not choose
+	DeleteReferencePolicy string
+	// RecurringSchedulerInterval is how often due recurring transactions are recorded, zero disables the scheduler
+	RecurringSchedulerInterval time.Duration
+	// BudgetRolloverInterval is how often recurring budgets are moved into their next period, zero disables it
+	BudgetRolloverInterval time.Duration
+	// NotificationChannels are where notifications are delivered besides being stored: in_app, email and webhook
+	NotificationChannels      []string
+	NotificationWebhookURL    string
+	NotificationWebhookSecret string
+	// ExchangeRateProvider is where missing exchange rates are fetched from: http, csv or none
+	ExchangeRateProvider string
+	ExchangeRateURL      string
+	ExchangeRateFile     string
 }
 
+// RateLimitPolicy allows Limit requests per client within a sliding Window
+type RateLimitPolicy struct {
+	Limit  int
+	Window time.Duration
+}
+
+// Named rate limit policies, overridable through RATE_LIMITS
+const (
+	RateLimitGlobal  = "global"
+	RateLimitAuth    = "auth"
+	RateLimitSignup  = "signup"
+	RateLimitReports = "reports"
+)
+
+var defaultRateLimits = map[string]RateLimitPolicy{
+	RateLimitGlobal:  {Limit: 300, Window: time.Minute},
+	RateLimitAuth:    {Limit: 20, Window: time.Minute},
+	RateLimitSignup:  {Limit: 5, Window: time.Hour},
+	RateLimitReports: {Limit: 30, Window: time.Minute},
+}
+
+// Supported values for AUTH_MODE
+const (
+	// AuthModeSession looks up opaque session tokens in the database on every request
+	AuthModeSession = "session"
+	// AuthModeJWT issues signed access tokens that are verified without a database round trip
+	AuthModeJWT = "jwt"
+)
+
+// Supported values for EMAIL_VERIFICATION_POLICY
+const (
+	// EmailVerificationNone lets unverified users do everything
+	EmailVerificationNone = "none"
+	// EmailVerificationRestrict lets unverified users sign in but keeps them out of their financial data
+	EmailVerificationRestrict = "restrict"
+	// EmailVerificationBlock refuses to sign in unverified users
+	EmailVerificationBlock = "block"
+)
+
+// What happens to the transactions pointing at a category or budget when it is deleted
+const (
+	// DeleteReferenceNullify clears the reference and keeps the transactions
+	DeleteReferenceNullify = "nullify"
+	// DeleteReferenceReassign moves the transactions to another category or budget of the same user
+	DeleteReferenceReassign = "reassign"
+	// DeleteReferenceBlock refuses the delete while any transaction still points at it
+	DeleteReferenceBlock = "block"
+)
+
 func NewConfig() (*AppConfig, error) {
 	if err := godotenv.Load(); err != nil {
 		return nil, err
@@ -33,7 +113,118 @@ func NewConfig() (*AppConfig, error) {
 		RedisHost:     os.Getenv("REDIS_HOST"),
 		RedisPassword: os.Getenv("REDIS_PASSWORD"),
 		DBPassword:    os.Getenv("DB_PASSWORD"),
+		AppURL:        getEnv("APP_URL", "http://localhost:8080"),
+		MailDriver:    getEnv("MAIL_DRIVER", "log"),
+		MailFrom:      getEnv("MAIL_FROM", "no-reply@budgetmax.local"),
+		MailOutputDir: getEnv("MAIL_OUTPUT_DIR", "logs/mail"),
+		AuthMode:      getEnv("AUTH_MODE", AuthModeSession),
+		JWTKeyID:      getEnv("JWT_KEY_ID", "default"),
+		JWTAlgorithm:  getEnv("JWT_ALGORITHM", "HS256"),
+		JWTKeysDir:    getEnv("JWT_KEYS_DIR", "keys/jwt"),
+
+		EmailVerificationPolicy: getEnv("EMAIL_VERIFICATION_POLICY", EmailVerificationNone),
+		DeleteReferencePolicy:   getEnv("DELETE_REFERENCE_POLICY", DeleteReferenceNullify),
+
+		NotificationWebhookURL:    os.Getenv("NOTIFICATION_WEBHOOK_URL"),
+		NotificationWebhookSecret: os.Getenv("NOTIFICATION_WEBHOOK_SECRET"),
+
+		ExchangeRateProvider: getEnv("EXCHANGE_RATE_PROVIDER", "http"),
+		ExchangeRateURL:      getEnv("EXCHANGE_RATE_URL", "https://api.frankfurter.app"),
+		ExchangeRateFile:     os.Getenv("EXCHANGE_RATE_FILE"),
+	}
+
+	switch Config.EmailVerificationPolicy {
+	case EmailVerificationNone, EmailVerificationRestrict, EmailVerificationBlock:
+	default:
+		return nil, fmt.Errorf("unsupported EMAIL_VERIFICATION_POLICY %q", Config.EmailVerificationPolicy)
+	}
+
+	// Reassigning needs a target, so only a request can ask for it
+	switch Config.DeleteReferencePolicy {
+	case DeleteReferenceNullify, DeleteReferenceBlock:
+	default:
+		return nil, fmt.Errorf("unsupported DELETE_REFERENCE_POLICY %q", Config.DeleteReferencePolicy)
+	}
+
+	rotationInterval, err := time.ParseDuration(getEnv("JWT_KEY_ROTATION_INTERVAL", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_KEY_ROTATION_INTERVAL: %w", err)
+	}
+	Config.JWTKeyRotationInterval = rotationInterval
+
+	schedulerInterval, err := time.ParseDuration(getEnv("RECURRING_SCHEDULER_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RECURRING_SCHEDULER_INTERVAL: %w", err)
+	}
+	Config.RecurringSchedulerInterval = schedulerInterval
+
+	rolloverInterval, err := time.ParseDuration(getEnv("BUDGET_ROLLOVER_INTERVAL", "5m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid BUDGET_ROLLOVER_INTERVAL: %w", err)
+	}
+	Config.BudgetRolloverInterval = rolloverInterval
+
+	for _, channel := range strings.Split(getEnv("NOTIFICATION_CHANNELS", "in_app"), ",") {
+		if channel = strings.TrimSpace(channel); channel != "" {
+			Config.NotificationChannels = append(Config.NotificationChannels, channel)
+		}
+	}
+
+	rateLimits, err := parseRateLimits(os.Getenv("RATE_LIMITS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMITS: %w", err)
+	}
+	Config.RateLimits = rateLimits
+
+	if Config.AuthMode != AuthModeSession && Config.AuthMode != AuthModeJWT {
+		return nil, fmt.Errorf("unsupported AUTH_MODE %q", Config.AuthMode)
 	}
 
 	return Config, nil
 }
+
+// getEnv returns the value of the environment variable or the fallback when it is unset
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// parseRateLimits reads a comma separated list of name=limit/window entries, e.g.
+// "auth=20/1m,reports=30/1m", on top of the default policies
+func parseRateLimits(value string) (map[string]RateLimitPolicy, error) {
+	policies := make(map[string]RateLimitPolicy, len(defaultRateLimits))
+	for name, policy := range defaultRateLimits {
+		policies[name] = policy
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, rule, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q is not name=limit/window", entry)
+		}
+		limitStr, windowStr, ok := strings.Cut(rule, "/")
+		if !ok {
+			return nil, fmt.Errorf("entry %q is not name=limit/window", entry)
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("entry %q has an invalid limit", entry)
+		}
+		window, err := time.ParseDuration(windowStr)
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("entry %q has an invalid window", entry)
+		}
+
+		policies[strings.TrimSpace(name)] = RateLimitPolicy{Limit: limit, Window: window}
+	}
+
+	return policies, nil
+}
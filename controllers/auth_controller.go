@@ -8,6 +8,7 @@ import (
 	"github.com/AlsoShantanuBorkar/budget_max/services"
 	"github.com/AlsoShantanuBorkar/budget_max/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type AuthControllerInterface interface {
@@ -19,6 +20,16 @@ type AuthControllerInterface interface {
 	Verify2FA(c *gin.Context)
 	Disable2FA(c *gin.Context)
 	LoginWith2FA(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	GetSessions(c *gin.Context)
+	RevokeSession(c *gin.Context)
+	RevokeOtherSessions(c *gin.Context)
+	RegenerateRecoveryCodes(c *gin.Context)
+	GetRecoveryCodesCount(c *gin.Context)
+	Reauthenticate(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	ResendVerificationEmail(c *gin.Context)
 }
 
 type AuthController struct {
@@ -79,6 +90,9 @@ func (ctrl *AuthController) Login(c *gin.Context) {
        response, serviceErr := ctrl.service.Login(c, &req)
        if serviceErr != nil {
 	       appErr := errors.NewUnauthorizedError(serviceErr.Message, serviceErr, )
+	       if serviceErr.Code == http.StatusForbidden {
+		       appErr = errors.NewForbiddenError(serviceErr.Message, serviceErr)
+	       }
 	       c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
@@ -94,13 +108,18 @@ func (ctrl *AuthController) Login(c *gin.Context) {
 	       return
        }
 
+       data := gin.H{
+	       "session": response.Session,
+	       "refresh": response.Refresh,
+	       "user_id": response.UserID,
+       }
+       if response.AccessToken != "" {
+	       data["access_token"] = response.AccessToken
+       }
+
        c.JSON(http.StatusOK, gin.H{
 	       "message": "Login successful",
-	       "data": gin.H{
-		       "session": response.Session,
-		       "refresh": response.Refresh,
-		       "user_id": response.UserID,
-	       },
+	       "data":    data,
        })
 }
 
@@ -145,13 +164,18 @@ func (ctrl *AuthController) RefreshToken(c *gin.Context) {
 	       return
        }
 
+       data := gin.H{
+	       "session": response.Session,
+	       "refresh": response.Refresh,
+	       "user_id": response.UserID,
+       }
+       if response.AccessToken != "" {
+	       data["access_token"] = response.AccessToken
+       }
+
        c.JSON(http.StatusOK, gin.H{
 	       "message": "Token refreshed successfully",
-	       "data": gin.H{
-		       "session": response.Session,
-		       "refresh": response.Refresh,
-		       "user_id": response.UserID,
-	       },
+	       "data":    data,
        })
 }
 
@@ -202,7 +226,7 @@ func (ctrl *AuthController) Verify2FA(c *gin.Context) {
 	       return
        }
 
-       serviceErr := ctrl.service.Verify2FA(c, &request, userId)
+       recoveryCodes, serviceErr := ctrl.service.Verify2FA(c, &request, userId)
        if serviceErr != nil {
 	       appErr := errors.NewUnauthorizedError(serviceErr.Message, serviceErr, )
 	       c.Error(appErr)
@@ -212,6 +236,9 @@ func (ctrl *AuthController) Verify2FA(c *gin.Context) {
 
        c.JSON(http.StatusOK, gin.H{
 	       "message": "2FA verified successfully",
+	       "data": gin.H{
+		       "recovery_codes": recoveryCodes,
+	       },
        })
 }
 
@@ -255,18 +282,348 @@ func (ctrl *AuthController) LoginWith2FA(c *gin.Context) {
 
        response, serviceErr := ctrl.service.LoginWith2FA(c, &request)
        if serviceErr != nil {
-	       appErr := errors.NewUnauthorizedError(serviceErr.Message, serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 	       c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
        }
 
+       data := gin.H{
+	       "session": response.Session,
+	       "refresh": response.Refresh,
+	       "user_id": response.UserID,
+       }
+       if response.AccessToken != "" {
+	       data["access_token"] = response.AccessToken
+       }
+
        c.JSON(http.StatusOK, gin.H{
 	       "message": "2FA login successful",
-	       "data": gin.H{
-		       "session": response.Session,
-		       "refresh": response.Refresh,
-		       "user_id": response.UserID,
-	       },
+	       "data":    data,
        })
 }
+
+func (ctrl *AuthController) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	serviceErr := ctrl.service.ForgotPassword(c, &req)
+	if serviceErr != nil {
+		appErr := errors.NewInternalError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "If an account exists for this email, a password reset link has been sent",
+	})
+}
+
+func (ctrl *AuthController) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	serviceErr := ctrl.service.ResetPassword(c, &req)
+	if serviceErr != nil {
+		appErr := errors.NewBadRequestError(serviceErr.Message, serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password reset successfully",
+	})
+}
+
+func (ctrl *AuthController) GetSessions(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	sessionId, ok := utils.ParseSessionID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid session ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	sessions, serviceErr := ctrl.service.GetSessions(c, userId, sessionId)
+	if serviceErr != nil {
+		appErr := errors.NewInternalError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sessions fetched successfully",
+		"data":    sessions,
+	})
+}
+
+func (ctrl *AuthController) RevokeSession(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	sessionIdStr := c.Param("id")
+	sessionId, err := uuid.Parse(sessionIdStr)
+	if err != nil {
+		appErr := errors.NewBadRequestError("Invalid session ID format", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	serviceErr := ctrl.service.RevokeSession(c, userId, sessionId)
+	if serviceErr != nil {
+		appErr := errors.NewNotFoundError("session", serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked successfully",
+	})
+}
+
+func (ctrl *AuthController) RevokeOtherSessions(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	sessionId, ok := utils.ParseSessionID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid session ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	revoked, serviceErr := ctrl.service.RevokeOtherSessions(c, userId, sessionId)
+	if serviceErr != nil {
+		appErr := errors.NewInternalError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Other sessions revoked successfully",
+		"data": gin.H{
+			"revoked": revoked,
+		},
+	})
+}
+
+func (ctrl *AuthController) RegenerateRecoveryCodes(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	var request models.TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if err := utils.GetValidator().Struct(request); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	recoveryCodes, serviceErr := ctrl.service.RegenerateRecoveryCodes(c, &request, userId)
+	if serviceErr != nil {
+		appErr := errors.NewUnauthorizedError(serviceErr.Message, serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Recovery codes regenerated successfully",
+		"data": gin.H{
+			"recovery_codes": recoveryCodes,
+		},
+	})
+}
+
+func (ctrl *AuthController) GetRecoveryCodesCount(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	remaining, serviceErr := ctrl.service.GetRecoveryCodesCount(c, userId)
+	if serviceErr != nil {
+		appErr := errors.NewInternalError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Recovery codes fetched successfully",
+		"data": gin.H{
+			"remaining": remaining,
+		},
+	})
+}
+
+func (ctrl *AuthController) Reauthenticate(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	sessionId, ok := utils.ParseSessionID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid session ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	var request models.ReauthRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if err := utils.GetValidator().Struct(request); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	response, serviceErr := ctrl.service.Reauthenticate(c, &request, userId, sessionId)
+	if serviceErr != nil {
+		appErr := errors.NewUnauthorizedError(serviceErr.Message, serviceErr)
+		if serviceErr.Code == http.StatusTooManyRequests {
+			appErr = errors.NewTooManyRequestsError(serviceErr.Message, serviceErr)
+		}
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Re-authenticated successfully",
+		"data":    response,
+	})
+}
+
+func (ctrl *AuthController) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	serviceErr := ctrl.service.VerifyEmail(c, &req)
+	if serviceErr != nil {
+		appErr := errors.NewBadRequestError(serviceErr.Message, serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
+	})
+}
+
+func (ctrl *AuthController) ResendVerificationEmail(c *gin.Context) {
+	var req models.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	serviceErr := ctrl.service.ResendVerificationEmail(c, &req)
+	if serviceErr != nil {
+		appErr := errors.NewInternalError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "If an unverified account exists for this email, a verification link has been sent",
+	})
+}
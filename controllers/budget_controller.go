@@ -136,14 +136,28 @@ func (ctrl *BudgetController) DeleteBudget(c *gin.Context) {
 		return
 	}
 
-	serviceErr := ctrl.service.DeleteBudget(c, budgetId, userId)
-	if serviceErr != nil {
-		appErr := errors.NewInternalError(serviceErr, )
+	var req models.DeleteBudgetRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
 		c.Error(appErr)
 		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 		return
 	}
 
+	serviceErr := ctrl.service.DeleteBudget(c, &req, budgetId, userId)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, serviceErrorResponse(serviceErr))
+		return
+	}
+
        c.JSON(http.StatusNoContent, gin.H{
 	       "message": "Budget deleted successfully",
        })
@@ -158,18 +172,20 @@ func (ctrl *BudgetController) GetBudgetsByUserID(c *gin.Context) {
 		return
 	}
 
-	budgets, serviceErr := ctrl.service.GetBudgetsByUserID(c, userId)
+	pageReq, ok := bindPageRequest(c)
+	if !ok {
+		return
+	}
+
+	budgets, serviceErr := ctrl.service.GetBudgetsByUserID(c, pageReq, userId)
 	if serviceErr != nil {
-		appErr := errors.NewInternalError(serviceErr, )
+		appErr := appErrorFromServiceError(serviceErr)
 		c.Error(appErr)
 		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 		return
 	}
 
-       c.JSON(http.StatusOK, gin.H{
-	       "message": "Budgets fetched successfully",
-	       "data":    budgets,
-       })
+       c.JSON(http.StatusOK, pageResponse("Budgets fetched successfully", budgets))
 }
 
 func (ctrl *BudgetController) GetBudgetByID(c *gin.Context) {
@@ -77,18 +77,20 @@ func (ctrl *CategoryController) GetAllCategories(c *gin.Context) {
 	       return
        }
 
-       categories, serviceErr := ctrl.service.GetCategoriesByUserID(c, userId)
+	pageReq, ok := bindPageRequest(c)
+	if !ok {
+		return
+	}
+
+       categories, serviceErr := ctrl.service.GetCategoriesByUserID(c, pageReq, userId)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 		   c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
        }
 
-       c.JSON(http.StatusOK, gin.H{
-	       "message": "Categories fetched successfully",
-	       "data":    categories,
-       })
+       c.JSON(http.StatusOK, pageResponse("Categories fetched successfully", categories))
 }
 
 func (ctrl *CategoryController) GetCategoryByID(c *gin.Context) {
@@ -189,13 +191,27 @@ func (ctrl *CategoryController) DeleteCategory(c *gin.Context) {
 	       return
        }
 
-       serviceErr := ctrl.service.DeleteCategory(c, categoryId, userId)
-       if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
-		   c.Error(appErr)
-	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
-	       return
-       }
+	var req models.DeleteCategoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	serviceErr := ctrl.service.DeleteCategory(c, &req, categoryId, userId)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, serviceErrorResponse(serviceErr))
+		return
+	}
 
        c.JSON(http.StatusNoContent, gin.H{
 	       "message": "Category deleted successfully",
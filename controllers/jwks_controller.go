@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSControllerInterface interface {
+	GetJWKS(c *gin.Context)
+}
+
+type JWKSController struct {
+	keys *utils.SigningKeyRing
+}
+
+func NewJWKSController(keys *utils.SigningKeyRing) *JWKSController {
+	return &JWKSController{keys: keys}
+}
+
+// GetJWKS publishes the public signing keys so other services can verify our tokens. The
+// body is a bare JWK set rather than the usual message/data envelope, as clients expect.
+func (ctrl *JWKSController) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(utils.JWKSMaxAge.Seconds())))
+	c.JSON(http.StatusOK, ctrl.keys.JWKS())
+}
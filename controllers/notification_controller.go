@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/AlsoShantanuBorkar/budget_max/services"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NotificationControllerInterface interface {
+	GetNotifications(c *gin.Context)
+	MarkNotificationRead(c *gin.Context)
+	MarkAllNotificationsRead(c *gin.Context)
+}
+
+type NotificationController struct {
+	service services.NotificationServiceInterface
+}
+
+func NewNotificationController(service services.NotificationServiceInterface) *NotificationController {
+	return &NotificationController{
+		service: service,
+	}
+}
+
+func (ctrl *NotificationController) GetNotifications(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	var req models.NotificationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	pageReq, ok := bindPageRequest(c)
+	if !ok {
+		return
+	}
+
+	notifications, serviceErr := ctrl.service.GetNotifications(c, &req, pageReq, userId)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("Notifications fetched successfully", notifications))
+}
+
+func (ctrl *NotificationController) MarkNotificationRead(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		appErr := errors.NewBadRequestError("Invalid notification ID format", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if serviceErr := ctrl.service.MarkNotificationRead(c, id, userId); serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notification marked as read",
+	})
+}
+
+func (ctrl *NotificationController) MarkAllNotificationsRead(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if serviceErr := ctrl.service.MarkAllNotificationsRead(c, userId); serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "All notifications marked as read",
+	})
+}
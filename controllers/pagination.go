@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// bindPageRequest reads the cursor, limit and sort query parameters and writes the error response
+// itself when they are invalid
+func bindPageRequest(c *gin.Context) (*models.PageRequest, bool) {
+	var req models.PageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid pagination parameters", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return nil, false
+	}
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid pagination parameters", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return nil, false
+	}
+	return &req, true
+}
+
+// pageResponse is the envelope every paginated list is returned in. next_cursor is null on the last page.
+func pageResponse[T any](message string, page *models.Page[T]) gin.H {
+	return gin.H{
+		"message":     message,
+		"data":        page.Items,
+		"next_cursor": page.NextCursor,
+	}
+}
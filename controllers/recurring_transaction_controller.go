@@ -0,0 +1,325 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/AlsoShantanuBorkar/budget_max/services"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RecurringTransactionControllerInterface interface {
+	CreateRecurringTransaction(c *gin.Context)
+	UpdateRecurringTransaction(c *gin.Context)
+	DeleteRecurringTransaction(c *gin.Context)
+	GetRecurringTransactionsByUserID(c *gin.Context)
+	GetRecurringTransactionByID(c *gin.Context)
+	PreviewOccurrences(c *gin.Context)
+	SetOccurrenceException(c *gin.Context)
+	DeleteOccurrenceException(c *gin.Context)
+}
+
+type RecurringTransactionController struct {
+	service services.RecurringTransactionServiceInterface
+}
+
+func NewRecurringTransactionController(service services.RecurringTransactionServiceInterface) *RecurringTransactionController {
+	return &RecurringTransactionController{
+		service: service,
+	}
+}
+
+func (ctrl *RecurringTransactionController) CreateRecurringTransaction(c *gin.Context) {
+	var req models.CreateRecurringTransactionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	rt, serviceErr := ctrl.service.CreateRecurringTransaction(c, &req, userId)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Recurring transaction created successfully",
+		"data":    rt,
+	})
+}
+
+func (ctrl *RecurringTransactionController) UpdateRecurringTransaction(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	var req models.UpdateRecurringTransactionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		appErr := errors.NewBadRequestError("Invalid recurring transaction ID format", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	rt, serviceErr := ctrl.service.UpdateRecurringTransaction(c, &req, id, userId)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Recurring transaction updated successfully",
+		"data":    rt,
+	})
+}
+
+func (ctrl *RecurringTransactionController) DeleteRecurringTransaction(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		appErr := errors.NewBadRequestError("Invalid recurring transaction ID format", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if serviceErr := ctrl.service.DeleteRecurringTransaction(c, id, userId); serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{
+		"message": "Recurring transaction deleted successfully",
+	})
+}
+
+func (ctrl *RecurringTransactionController) GetRecurringTransactionsByUserID(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	pageReq, ok := bindPageRequest(c)
+	if !ok {
+		return
+	}
+
+	rts, serviceErr := ctrl.service.GetRecurringTransactionsByUserID(c, pageReq, userId)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("Recurring transactions fetched successfully", rts))
+}
+
+func (ctrl *RecurringTransactionController) GetRecurringTransactionByID(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		appErr := errors.NewBadRequestError("Invalid recurring transaction ID format", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	rt, serviceErr := ctrl.service.GetRecurringTransactionByID(c, id, userId)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Recurring transaction fetched successfully",
+		"data":    rt,
+	})
+}
+
+func (ctrl *RecurringTransactionController) PreviewOccurrences(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		appErr := errors.NewBadRequestError("Invalid recurring transaction ID format", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	var req models.PreviewRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	occurrences, serviceErr := ctrl.service.PreviewOccurrences(c, &req, id, userId)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Occurrences fetched successfully",
+		"data":    occurrences,
+	})
+}
+
+func (ctrl *RecurringTransactionController) SetOccurrenceException(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		appErr := errors.NewBadRequestError("Invalid recurring transaction ID format", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	var req models.OccurrenceExceptionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	exception, serviceErr := ctrl.service.SetOccurrenceException(c, &req, id, c.Param("date"), userId)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Occurrence updated successfully",
+		"data":    exception,
+	})
+}
+
+func (ctrl *RecurringTransactionController) DeleteOccurrenceException(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		appErr := errors.NewBadRequestError("Invalid recurring transaction ID format", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if serviceErr := ctrl.service.DeleteOccurrenceException(c, id, c.Param("date"), userId); serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{
+		"message": "Occurrence restored successfully",
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models/reports"
 	"github.com/AlsoShantanuBorkar/budget_max/services"
 	"github.com/AlsoShantanuBorkar/budget_max/utils"
 	"github.com/gin-gonic/gin"
@@ -22,6 +23,8 @@ type ReportsControllerInterface interface {
 	GetDailyAverageSummary(c *gin.Context)
 	GetTopCategories(c *gin.Context)
 	GetAllCategoriesSummary(c *gin.Context)
+	GetAllTagsSummary(c *gin.Context)
+	GetTimeSeries(c *gin.Context)
 }
 
 type ReportsController struct {
@@ -52,9 +55,14 @@ func (ctrl *ReportsController) GetBudgetSummary(c *gin.Context) {
 	       return
        }
 
-       summary, serviceErr := ctrl.service.GetBudgetSummary(c, budgetID, userID)
+       currency, ok := reportCurrency(c)
+       if !ok {
+	       return
+       }
+
+       summary, serviceErr := ctrl.service.GetBudgetSummary(c, budgetID, userID, currency)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 		   c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
@@ -91,9 +99,14 @@ func (ctrl *ReportsController) GetWeeklySummary(c *gin.Context) {
 	       return
        }
 
-       summary, serviceErr := ctrl.service.GetWeeklySummary(c, userID, startDate, endDate)
+       currency, ok := reportCurrency(c)
+       if !ok {
+	       return
+       }
+
+       summary, serviceErr := ctrl.service.GetWeeklySummary(c, userID, startDate, endDate, currency)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 		   c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
@@ -120,9 +133,14 @@ func (ctrl *ReportsController) GetMonthlySummary(c *gin.Context) {
 	       return
        }
 
-       summary, serviceErr := ctrl.service.GetMonthlySummary(c, userID, month)
+       currency, ok := reportCurrency(c)
+       if !ok {
+	       return
+       }
+
+       summary, serviceErr := ctrl.service.GetMonthlySummary(c, userID, month, currency)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 		   c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
@@ -149,9 +167,14 @@ func (ctrl *ReportsController) GetYearlySummary(c *gin.Context) {
 	       return
        }
 
-       summary, serviceErr := ctrl.service.GetYearlySummary(c, userID, year)
+       currency, ok := reportCurrency(c)
+       if !ok {
+	       return
+       }
+
+       summary, serviceErr := ctrl.service.GetYearlySummary(c, userID, year, currency)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 		   c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
@@ -178,9 +201,14 @@ func (ctrl *ReportsController) GetCategorySummary(c *gin.Context) {
 	       return
        }
 
-       summary, serviceErr := ctrl.service.GetCategorySummary(c, userID, categoryID)
+       currency, ok := reportCurrency(c)
+       if !ok {
+	       return
+       }
+
+       summary, serviceErr := ctrl.service.GetCategorySummary(c, userID, categoryID, currency)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 		   c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
@@ -217,9 +245,14 @@ func (ctrl *ReportsController) GetCustomDateRangeSummary(c *gin.Context) {
 	       return
        }
 
-       summary, serviceErr := ctrl.service.GetCustomDateRangeSummary(c, userID, startDate, endDate)
+       currency, ok := reportCurrency(c)
+       if !ok {
+	       return
+       }
+
+       summary, serviceErr := ctrl.service.GetCustomDateRangeSummary(c, userID, startDate, endDate, currency)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 		   c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
@@ -256,9 +289,14 @@ func (ctrl *ReportsController) GetDailyAverageSummary(c *gin.Context) {
 	       return
        }
 
-       summary, serviceErr := ctrl.service.GetDailyAverageSummary(c, userID, startDate, endDate)
+       currency, ok := reportCurrency(c)
+       if !ok {
+	       return
+       }
+
+       summary, serviceErr := ctrl.service.GetDailyAverageSummary(c, userID, startDate, endDate, currency)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 		   c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
@@ -293,9 +331,14 @@ func (ctrl *ReportsController) GetTopCategories(c *gin.Context) {
 	       return
        }
 
-       categories, serviceErr := ctrl.service.GetTopCategories(c, userID, limit, transactionType)
+       currency, ok := reportCurrency(c)
+       if !ok {
+	       return
+       }
+
+       categories, serviceErr := ctrl.service.GetTopCategories(c, userID, limit, transactionType, currency)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 		   c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
@@ -313,9 +356,14 @@ func (ctrl *ReportsController) GetAllCategoriesSummary(c *gin.Context) {
 	       return
        }
 
-       summaries, serviceErr := ctrl.service.GetAllCategoriesSummary(c, userID)
+       currency, ok := reportCurrency(c)
+       if !ok {
+	       return
+       }
+
+       summaries, serviceErr := ctrl.service.GetAllCategoriesSummary(c, userID, currency)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 		   c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
@@ -323,3 +371,95 @@ func (ctrl *ReportsController) GetAllCategoriesSummary(c *gin.Context) {
 
        c.JSON(http.StatusOK, summaries)
 }
+
+func (ctrl *ReportsController) GetAllTagsSummary(c *gin.Context) {
+	userID, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	currency, ok := reportCurrency(c)
+	if !ok {
+		return
+	}
+
+	summaries, serviceErr := ctrl.service.GetAllTagsSummary(c, userID, currency)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, summaries)
+}
+
+func (ctrl *ReportsController) GetTimeSeries(c *gin.Context) {
+	userID, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	var req reports.TimeSeriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.Start)
+	if err != nil {
+		appErr := errors.NewBadRequestError("Invalid start date format. Use YYYY-MM-DD", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+	endDate, err := time.Parse("2006-01-02", req.End)
+	if err != nil {
+		appErr := errors.NewBadRequestError("Invalid end date format. Use YYYY-MM-DD", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+	if endDate.Before(startDate) {
+		appErr := errors.NewBadRequestError("End date must not be before start date", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	series, serviceErr := ctrl.service.GetTimeSeries(c, userID, req.Interval, startDate, endDate, req.GroupBy, req.Timezone, req.Currency)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// reportCurrency reads the optional currency query parameter that reports are converted into
+func reportCurrency(c *gin.Context) (string, bool) {
+	currency := c.Query("currency")
+	if err := utils.GetValidator().Var(currency, "omitempty,iso4217"); err != nil {
+		appErr := errors.NewBadRequestError("Invalid currency. Use an ISO 4217 code such as USD", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return "", false
+	}
+	return currency, true
+}
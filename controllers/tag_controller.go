@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/AlsoShantanuBorkar/budget_max/services"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TagControllerInterface interface {
+	CreateTag(c *gin.Context)
+	GetAllTags(c *gin.Context)
+	GetTagByID(c *gin.Context)
+	UpdateTag(c *gin.Context)
+	DeleteTag(c *gin.Context)
+}
+
+type TagController struct {
+	service services.TagServiceInterface
+}
+
+func NewTagController(service services.TagServiceInterface) *TagController {
+	return &TagController{
+		service: service,
+	}
+}
+
+func (ctrl *TagController) CreateTag(c *gin.Context) {
+	var req models.CreateTagRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	tag, serviceErr := ctrl.service.CreateTag(c, &req, userId)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Tag created successfully",
+		"data":    tag,
+	})
+}
+
+func (ctrl *TagController) GetAllTags(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	pageReq, ok := bindPageRequest(c)
+	if !ok {
+		return
+	}
+
+	tags, serviceErr := ctrl.service.GetTagsByUserID(c, pageReq, userId)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("Tags fetched successfully", tags))
+}
+
+func (ctrl *TagController) GetTagByID(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	tagId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		appErr := errors.NewBadRequestError("Invalid tag ID format", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	tag, serviceErr := ctrl.service.GetTagByID(c, tagId, userId)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tag fetched successfully",
+		"data":    tag,
+	})
+}
+
+func (ctrl *TagController) UpdateTag(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	var req models.UpdateTagRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := errors.NewBadRequestError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if err := utils.GetValidator().Struct(req); err != nil {
+		appErr := errors.NewValidationError("Invalid Request", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	tagId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		appErr := errors.NewBadRequestError("Invalid tag ID format", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	tag, serviceErr := ctrl.service.UpdateTag(c, &req, tagId, userId)
+	if serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tag updated successfully",
+		"data":    tag,
+	})
+}
+
+func (ctrl *TagController) DeleteTag(c *gin.Context) {
+	userId, ok := utils.ParseUserID(c)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid user ID", nil)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	tagId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		appErr := errors.NewBadRequestError("Invalid tag ID format", err)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if serviceErr := ctrl.service.DeleteTag(c, tagId, userId); serviceErr != nil {
+		appErr := appErrorFromServiceError(serviceErr)
+		c.Error(appErr)
+		c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{
+		"message": "Tag deleted successfully",
+	})
+}
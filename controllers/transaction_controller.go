@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/AlsoShantanuBorkar/budget_max/errors"
@@ -60,7 +61,7 @@ func (ctrl *TransactionController) CreateTransaction(c *gin.Context) {
 
        txn, err := ctrl.service.CreateTransaction(c, &req, userId)
        if err != nil {
-	       appErr := errors.NewInternalError(err, )
+	       appErr := appErrorFromServiceError(err)
 	       c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
@@ -107,7 +108,7 @@ func (ctrl *TransactionController) UpdateTransaction(c *gin.Context) {
 
        updatedTransaction, serviceErr := ctrl.service.UpdateTransaction(c, &req, txnId, userId)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 	       c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
@@ -159,18 +160,20 @@ func (ctrl *TransactionController) GetTransactionsByUserID(c *gin.Context) {
 	       return
        }
 
-       txns, serviceErr := ctrl.service.GetTransactionsByUserID(c, userId)
+	pageReq, ok := bindPageRequest(c)
+	if !ok {
+		return
+	}
+
+       txns, serviceErr := ctrl.service.GetTransactionsByUserID(c, pageReq, userId)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 	       c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
        }
 
-       c.JSON(http.StatusOK, gin.H{
-	       "message": "Transactions fetched successfully",
-	       "data":    txns,
-       })
+       c.JSON(http.StatusOK, pageResponse("Transactions fetched successfully", txns))
 }
 
 func (ctrl *TransactionController) GetTransactionByID(c *gin.Context) {
@@ -223,18 +226,20 @@ func (ctrl *TransactionController) GetTransactionsByBudget(c *gin.Context) {
 	       return
        }
 
-       txns, serviceErr := ctrl.service.GetTransactionsByBudget(c, budgetID, userId)
+	pageReq, ok := bindPageRequest(c)
+	if !ok {
+		return
+	}
+
+       txns, serviceErr := ctrl.service.GetTransactionsByBudget(c, budgetID, pageReq, userId)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 	       c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
        }
 
-       c.JSON(http.StatusOK, gin.H{
-	       "message": "Transactions fetched successfully",
-	       "data":    txns,
-       })
+       c.JSON(http.StatusOK, pageResponse("Transactions fetched successfully", txns))
 }
 
 func (ctrl *TransactionController) GetTransactionsByCategory(c *gin.Context) {
@@ -255,18 +260,20 @@ func (ctrl *TransactionController) GetTransactionsByCategory(c *gin.Context) {
 	       return
        }
 
-       txns, serviceErr := ctrl.service.GetTransactionsByCategory(c, categoryID, userId)
+	pageReq, ok := bindPageRequest(c)
+	if !ok {
+		return
+	}
+
+       txns, serviceErr := ctrl.service.GetTransactionsByCategory(c, categoryID, pageReq, userId)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 	       c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
        }
 
-       c.JSON(http.StatusOK, gin.H{
-	       "message": "Transactions fetched successfully",
-	       "data":    txns,
-       })
+       c.JSON(http.StatusOK, pageResponse("Transactions fetched successfully", txns))
 }
 
 func (ctrl *TransactionController) GetTransactionsByDateRange(c *gin.Context) {
@@ -308,18 +315,20 @@ func (ctrl *TransactionController) GetTransactionsByDateRange(c *gin.Context) {
 	       return
        }
 
-       txns, serviceErr := ctrl.service.GetTransactionsByDateRange(c, startDate, endDate, userId)
+	pageReq, ok := bindPageRequest(c)
+	if !ok {
+		return
+	}
+
+       txns, serviceErr := ctrl.service.GetTransactionsByDateRange(c, startDate, endDate, pageReq, userId)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 	       c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
        }
 
-       c.JSON(http.StatusOK, gin.H{
-	       "message": "Transactions fetched successfully",
-	       "data":    txns,
-       })
+       c.JSON(http.StatusOK, pageResponse("Transactions fetched successfully", txns))
 }
 
 func (ctrl *TransactionController) GetTransactionsByType(c *gin.Context) {
@@ -339,18 +348,20 @@ func (ctrl *TransactionController) GetTransactionsByType(c *gin.Context) {
 	       return
        }
 
-       txns, serviceErr := ctrl.service.GetTransactionsByType(c, transactionType, userId)
+	pageReq, ok := bindPageRequest(c)
+	if !ok {
+		return
+	}
+
+       txns, serviceErr := ctrl.service.GetTransactionsByType(c, transactionType, pageReq, userId)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 	       c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
        }
 
-       c.JSON(http.StatusOK, gin.H{
-	       "message": "Transactions fetched successfully",
-	       "data":    txns,
-       })
+       c.JSON(http.StatusOK, pageResponse("Transactions fetched successfully", txns))
 }
 
 func (ctrl *TransactionController) GetTransactionsByAmountRange(c *gin.Context) {
@@ -376,18 +387,20 @@ func (ctrl *TransactionController) GetTransactionsByAmountRange(c *gin.Context)
 	       return
        }
 
-       txns, serviceErr := ctrl.service.GetTransactionsByAmountRange(c, req.MinAmount, req.MaxAmount, userId)
+	pageReq, ok := bindPageRequest(c)
+	if !ok {
+		return
+	}
+
+       txns, serviceErr := ctrl.service.GetTransactionsByAmountRange(c, req.MinAmount, req.MaxAmount, pageReq, userId)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 	       c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
        }
 
-       c.JSON(http.StatusOK, gin.H{
-	       "message": "Transactions fetched successfully",
-	       "data":    txns,
-       })
+       c.JSON(http.StatusOK, pageResponse("Transactions fetched successfully", txns))
 }
 
 func (ctrl *TransactionController) GetTransactionsWithFilters(c *gin.Context) {
@@ -471,16 +484,46 @@ func (ctrl *TransactionController) GetTransactionsWithFilters(c *gin.Context) {
 	       filters["max_amount"] = *req.MaxAmount
        }
 
-       txns, serviceErr := ctrl.service.GetTransactionsWithFilters(c, filters, userId)
+	if req.Q != nil {
+		if q := strings.TrimSpace(*req.Q); q != "" {
+			filters["q"] = q
+		}
+	}
+
+	if len(req.TagIDs) > 0 {
+		tagIDs := make([]uuid.UUID, 0, len(req.TagIDs))
+		seen := make(map[uuid.UUID]bool, len(req.TagIDs))
+		for _, tagIDStr := range req.TagIDs {
+			tagID, err := uuid.Parse(tagIDStr)
+			if err != nil {
+				appErr := errors.NewBadRequestError("Invalid tag ID format", err)
+				c.Error(appErr)
+				c.JSON(appErr.Code, gin.H{"message": appErr.Message})
+				return
+			}
+			if !seen[tagID] {
+				seen[tagID] = true
+				tagIDs = append(tagIDs, tagID)
+			}
+		}
+		filters["tag_ids"] = tagIDs
+		if req.TagMatch != nil {
+			filters["tag_match"] = *req.TagMatch
+		}
+	}
+
+	pageReq, ok := bindPageRequest(c)
+	if !ok {
+		return
+	}
+
+       txns, serviceErr := ctrl.service.GetTransactionsWithFilters(c, filters, pageReq, userId)
        if serviceErr != nil {
-	       appErr := errors.NewInternalError(serviceErr, )
+	       appErr := appErrorFromServiceError(serviceErr)
 	       c.Error(appErr)
 	       c.JSON(appErr.Code, gin.H{"message": appErr.Message})
 	       return
        }
 
-       c.JSON(http.StatusOK, gin.H{
-	       "message": "Transactions fetched successfully",
-	       "data":    txns,
-       })
+       c.JSON(http.StatusOK, pageResponse("Transactions fetched successfully", txns))
 }
@@ -1,18 +1,28 @@
 package database
 
 import (
+	"errors"
+	"time"
+
 	appErrors "github.com/AlsoShantanuBorkar/budget_max/errors"
 	"github.com/AlsoShantanuBorkar/budget_max/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BudgetDatabaseServiceInterface interface {
 	CreateBudget(b *models.Budget) error
-	GetBudgetsByUser(userID uuid.UUID) ([]models.Budget, error)
+	GetBudgetsByUser(userID uuid.UUID, page PageQuery) ([]models.Budget, error)
 	UpdateBudget(id uuid.UUID, updates map[string]any) error
 	DeleteBudget(id uuid.UUID) error
+	DeleteBudgetAndReassign(id uuid.UUID, replacementID *uuid.UUID) error
 	GetBudgetByID(budgetId uuid.UUID, userId uuid.UUID) (*models.Budget, error)
+	GetBudgetPeriods(budgetID uuid.UUID) ([]models.BudgetPeriod, error)
+	GetBudgetsToRollOver(now time.Time, limit int) ([]models.Budget, error)
+	RollOverBudget(b *models.Budget, fromIndex int, period *models.BudgetPeriod) (bool, error)
+	SetBudgetCategories(budgetID uuid.UUID, categoryIDs []uuid.UUID) error
+	GetBudgetIDsByCategory(userID uuid.UUID, categoryID uuid.UUID) ([]uuid.UUID, error)
 }
 
 type BudgetDatabaseService struct {
@@ -23,24 +33,67 @@ func NewBudgetDatabaseService(db *gorm.DB) BudgetDatabaseServiceInterface {
 	return &BudgetDatabaseService{database: db}
 }
 
+// CreateBudget stores the budget together with its categories and first period
 func (s *BudgetDatabaseService) CreateBudget(budget *models.Budget) error {
-	if err := s.database.Create(budget).Error; err != nil {
+	err := s.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(budget).Error; err != nil {
+			return err
+		}
+		if err := createBudgetCategories(tx, budget.ID, budget.CategoryIDs); err != nil {
+			return err
+		}
+		return tx.Create(&models.BudgetPeriod{
+			ID:          uuid.New(),
+			BudgetID:    budget.ID,
+			StartDate:   budget.StartDate,
+			EndDate:     budget.EndDate,
+			Amount:      budget.Amount,
+			CarriedOver: budget.CarriedOver,
+			CreatedAt:   budget.CreatedAt,
+		}).Error
+	})
+	if err != nil {
 		return appErrors.NewDBError(err)
 	}
 	return nil
 }
 
-func (s *BudgetDatabaseService) GetBudgetsByUser(userID uuid.UUID) ([]models.Budget, error) {
+func (s *BudgetDatabaseService) GetBudgetsByUser(userID uuid.UUID, page PageQuery) ([]models.Budget, error) {
 	var budgets []models.Budget
-	err := s.database.Where("user_id = ?", userID).Find(&budgets).Error
+	err := page.apply(s.database.Where("user_id = ?", userID)).Find(&budgets).Error
 	if err != nil {
 		return nil, appErrors.NewDBError(err)
 	}
+	if err := s.loadCategoryIDs(budgets); err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
 	return budgets, nil
 }
 
+// UpdateBudget applies updates to the budget and keeps the record of its current period in step
 func (s *BudgetDatabaseService) UpdateBudget(id uuid.UUID, updates map[string]any) error {
-	if err := s.database.Model(&models.Budget{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+	err := s.database.Transaction(func(tx *gorm.DB) error {
+		var current models.Budget
+		if err := tx.First(&current, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Budget{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		var updated models.Budget
+		if err := tx.First(&updated, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.BudgetPeriod{}).
+			Where("budget_id = ? AND start_date = ?", id, current.StartDate).
+			Updates(map[string]any{
+				"start_date": updated.StartDate,
+				"end_date":   updated.EndDate,
+				"amount":     updated.Amount,
+			}).Error
+	})
+	if err != nil {
 		return appErrors.NewDBError(err)
 	}
 	return nil
@@ -53,11 +106,157 @@ func (s *BudgetDatabaseService) DeleteBudget(id uuid.UUID) error {
 	return nil
 }
 
+// DeleteBudgetAndReassign points the budget's transactions and recurring transactions at
+// replacementID, or clears their budget when it is nil, and deletes the budget in the same transaction
+func (s *BudgetDatabaseService) DeleteBudgetAndReassign(id uuid.UUID, replacementID *uuid.UUID) error {
+	err := s.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Transaction{}).Where("budget_id = ?", id).Update("budget_id", replacementID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.RecurringTransaction{}).Where("budget_id = ?", id).Update("budget_id", replacementID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Budget{}, "id = ?", id).Error
+	})
+	if err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
+
 func (s *BudgetDatabaseService) GetBudgetByID(budgetId uuid.UUID, userId uuid.UUID) (*models.Budget, error) {
 	var b models.Budget
 	err := s.database.First(&b, "id = ? AND user_id = ?", budgetId, userId).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, appErrors.NewDBError(err)
 	}
-	return &b, nil
+	budgets := []models.Budget{b}
+	if err := s.loadCategoryIDs(budgets); err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return &budgets[0], nil
+}
+
+// GetBudgetPeriods returns every period the budget has had, newest first
+func (s *BudgetDatabaseService) GetBudgetPeriods(budgetID uuid.UUID) ([]models.BudgetPeriod, error) {
+	var periods []models.BudgetPeriod
+	err := s.database.Where("budget_id = ?", budgetID).Order("start_date DESC").Find(&periods).Error
+	if err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return periods, nil
+}
+
+// GetBudgetsToRollOver returns up to limit recurring budgets whose current period ended before now
+func (s *BudgetDatabaseService) GetBudgetsToRollOver(now time.Time, limit int) ([]models.Budget, error) {
+	var budgets []models.Budget
+	err := s.database.
+		Where("recurring AND end_date < ?", now).
+		Order("end_date").
+		Limit(limit).
+		Find(&budgets).Error
+	if err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	if err := s.loadCategoryIDs(budgets); err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return budgets, nil
+}
+
+// RollOverBudget moves the budget from period fromIndex into period, which it records. It reports
+// false without changing anything when the budget is no longer at fromIndex, so two instances
+// rolling the same budget over cannot both advance it.
+func (s *BudgetDatabaseService) RollOverBudget(b *models.Budget, fromIndex int, period *models.BudgetPeriod) (bool, error) {
+	advanced := false
+	err := s.database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Budget{}).
+			Where("id = ? AND period_index = ?", b.ID, fromIndex).
+			Updates(map[string]any{
+				"start_date":   period.StartDate,
+				"end_date":     period.EndDate,
+				"carried_over": period.CarriedOver,
+				"period_index": fromIndex + 1,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		advanced = true
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(period).Error
+	})
+	if err != nil {
+		return false, appErrors.NewDBError(err)
+	}
+	return advanced, nil
+}
+
+// SetBudgetCategories replaces the categories the budget tracks
+func (s *BudgetDatabaseService) SetBudgetCategories(budgetID uuid.UUID, categoryIDs []uuid.UUID) error {
+	err := s.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.BudgetCategory{}, "budget_id = ?", budgetID).Error; err != nil {
+			return err
+		}
+		return createBudgetCategories(tx, budgetID, categoryIDs)
+	})
+	if err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
+
+// GetBudgetIDsByCategory returns the user's budgets that track the category
+func (s *BudgetDatabaseService) GetBudgetIDsByCategory(userID uuid.UUID, categoryID uuid.UUID) ([]uuid.UUID, error) {
+	var budgetIDs []uuid.UUID
+	err := s.database.Model(&models.BudgetCategory{}).
+		Joins("JOIN budgets ON budgets.id = budget_categories.budget_id").
+		Where("budgets.user_id = ? AND budget_categories.category_id = ?", userID, categoryID).
+		Pluck("budget_categories.budget_id", &budgetIDs).Error
+	if err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return budgetIDs, nil
+}
+
+func createBudgetCategories(tx *gorm.DB, budgetID uuid.UUID, categoryIDs []uuid.UUID) error {
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+	links := make([]models.BudgetCategory, len(categoryIDs))
+	for i, categoryID := range categoryIDs {
+		links[i] = models.BudgetCategory{BudgetID: budgetID, CategoryID: categoryID}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// loadCategoryIDs fills in CategoryIDs for every budget with one query
+func (s *BudgetDatabaseService) loadCategoryIDs(budgets []models.Budget) error {
+	if len(budgets) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(budgets))
+	for i := range budgets {
+		ids[i] = budgets[i].ID
+		budgets[i].CategoryIDs = []uuid.UUID{}
+	}
+
+	var links []models.BudgetCategory
+	if err := s.database.Where("budget_id IN ?", ids).Find(&links).Error; err != nil {
+		return err
+	}
+	byBudget := make(map[uuid.UUID][]uuid.UUID, len(budgets))
+	for _, link := range links {
+		byBudget[link.BudgetID] = append(byBudget[link.BudgetID], link.CategoryID)
+	}
+	for i := range budgets {
+		if categoryIDs, ok := byBudget[budgets[i].ID]; ok {
+			budgets[i].CategoryIDs = categoryIDs
+		}
+	}
+	return nil
 }
@@ -12,9 +12,10 @@ import (
 type CategoryDatabaseServiceInterface interface {
 	CreateCategory(cat *models.Category) error
 	GetCategoryByID(id uuid.UUID, userId uuid.UUID) (*models.Category, error)
-	GetUserCategories(userID uuid.UUID) ([]models.Category, error)
+	GetUserCategories(userID uuid.UUID, page PageQuery) ([]models.Category, error)
 	UpdateCategory(id uuid.UUID, updates map[string]interface{}) error
 	DeleteCategory(id uuid.UUID) error
+	DeleteCategoryAndReassign(id uuid.UUID, replacementID *uuid.UUID) error
 }
 
 type CategoryDatabaseService struct {
@@ -44,9 +45,9 @@ func (s *CategoryDatabaseService) GetCategoryByID(id uuid.UUID, userId uuid.UUID
 	return &cat, nil
 }
 
-func (s *CategoryDatabaseService) GetUserCategories(userID uuid.UUID) ([]models.Category, error) {
+func (s *CategoryDatabaseService) GetUserCategories(userID uuid.UUID, page PageQuery) ([]models.Category, error) {
 	var categories []models.Category
-	err := s.database.Where("user_id = ?", userID).Find(&categories).Error
+	err := page.apply(s.database.Where("user_id = ?", userID)).Find(&categories).Error
 	if err != nil {
 		return nil, appErrors.NewDBError(err)
 	}
@@ -66,3 +67,33 @@ func (s *CategoryDatabaseService) DeleteCategory(id uuid.UUID) error {
 	}
 	return nil
 }
+
+// DeleteCategoryAndReassign points the category's transactions, splits and recurring transactions at
+// replacementID, or clears their category when it is nil, and deletes the category in the same transaction
+func (s *CategoryDatabaseService) DeleteCategoryAndReassign(id uuid.UUID, replacementID *uuid.UUID) error {
+	err := s.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Transaction{}).Where("category_id = ?", id).Update("category_id", replacementID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.RecurringTransaction{}).Where("category_id = ?", id).Update("category_id", replacementID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TransactionSplit{}).Where("category_id = ?", id).Update("category_id", replacementID).Error; err != nil {
+			return err
+		}
+		// Budgets tracking the category keep tracking its transactions under the replacement
+		if replacementID != nil {
+			err := tx.Exec(`INSERT INTO budget_categories (budget_id, category_id)
+				SELECT budget_id, ? FROM budget_categories WHERE category_id = ?
+				ON CONFLICT DO NOTHING`, *replacementID, id).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&models.Category{}, "id = ?", id).Error
+	})
+	if err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	appErrors "github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EmailChangeTokenDatabaseServiceInterface interface {
+	CreateEmailChangeToken(token *models.EmailChangeToken) error
+	GetEmailChangeTokenByToken(tokenHash string) (*models.EmailChangeToken, error)
+	MarkEmailChangeTokenUsed(tokenID uuid.UUID) (bool, error)
+	InvalidateEmailChangeTokensByUserID(userID uuid.UUID) error
+}
+
+type EmailChangeTokenDatabaseService struct {
+	database *gorm.DB
+}
+
+func NewEmailChangeTokenDatabaseService(db *gorm.DB) EmailChangeTokenDatabaseServiceInterface {
+	return &EmailChangeTokenDatabaseService{database: db}
+}
+
+func (s *EmailChangeTokenDatabaseService) CreateEmailChangeToken(token *models.EmailChangeToken) error {
+	if err := s.database.Create(token).Error; err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
+
+func (s *EmailChangeTokenDatabaseService) GetEmailChangeTokenByToken(tokenHash string) (*models.EmailChangeToken, error) {
+	var token models.EmailChangeToken
+	err := s.database.First(&token, "token = ?", tokenHash).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return &token, nil
+}
+
+// MarkEmailChangeTokenUsed flips the token to used only if it is still unused and unexpired.
+// It reports false when another request consumed the token first.
+func (s *EmailChangeTokenDatabaseService) MarkEmailChangeTokenUsed(tokenID uuid.UUID) (bool, error) {
+	result := s.database.Model(&models.EmailChangeToken{}).
+		Where("id = ? AND used = false AND expires_at > ?", tokenID, time.Now()).
+		Update("used", true)
+	if result.Error != nil {
+		return false, appErrors.NewDBError(result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// InvalidateEmailChangeTokensByUserID marks every outstanding token of the user as used
+func (s *EmailChangeTokenDatabaseService) InvalidateEmailChangeTokensByUserID(userID uuid.UUID) error {
+	err := s.database.Model(&models.EmailChangeToken{}).
+		Where("user_id = ? AND used = false", userID).
+		Update("used", true).Error
+	if err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	appErrors "github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationTokenDatabaseServiceInterface interface {
+	CreateEmailVerificationToken(token *models.EmailVerificationToken) error
+	GetEmailVerificationTokenByToken(tokenHash string) (*models.EmailVerificationToken, error)
+	MarkEmailVerificationTokenUsed(tokenID uuid.UUID) (bool, error)
+	InvalidateEmailVerificationTokensByUserID(userID uuid.UUID) error
+}
+
+type EmailVerificationTokenDatabaseService struct {
+	database *gorm.DB
+}
+
+func NewEmailVerificationTokenDatabaseService(db *gorm.DB) EmailVerificationTokenDatabaseServiceInterface {
+	return &EmailVerificationTokenDatabaseService{database: db}
+}
+
+func (s *EmailVerificationTokenDatabaseService) CreateEmailVerificationToken(token *models.EmailVerificationToken) error {
+	if err := s.database.Create(token).Error; err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
+
+func (s *EmailVerificationTokenDatabaseService) GetEmailVerificationTokenByToken(tokenHash string) (*models.EmailVerificationToken, error) {
+	var token models.EmailVerificationToken
+	err := s.database.First(&token, "token = ?", tokenHash).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return &token, nil
+}
+
+// MarkEmailVerificationTokenUsed flips the token to used only if it is still unused and unexpired.
+// It reports false when another request consumed the token first.
+func (s *EmailVerificationTokenDatabaseService) MarkEmailVerificationTokenUsed(tokenID uuid.UUID) (bool, error) {
+	result := s.database.Model(&models.EmailVerificationToken{}).
+		Where("id = ? AND used = false AND expires_at > ?", tokenID, time.Now()).
+		Update("used", true)
+	if result.Error != nil {
+		return false, appErrors.NewDBError(result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// InvalidateEmailVerificationTokensByUserID marks every outstanding token of the user as used
+func (s *EmailVerificationTokenDatabaseService) InvalidateEmailVerificationTokensByUserID(userID uuid.UUID) error {
+	err := s.database.Model(&models.EmailVerificationToken{}).
+		Where("user_id = ? AND used = false", userID).
+		Update("used", true).Error
+	if err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"time"
+
+	appErrors "github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExchangeRateDatabaseServiceInterface interface {
+	SaveExchangeRates(rates []models.ExchangeRate) error
+	GetExchangeRate(from string, to string, since time.Time, until time.Time) (float64, bool, error)
+}
+
+type ExchangeRateDatabaseService struct {
+	database *gorm.DB
+}
+
+func NewExchangeRateDatabaseService(db *gorm.DB) ExchangeRateDatabaseServiceInterface {
+	return &ExchangeRateDatabaseService{database: db}
+}
+
+// SaveExchangeRates stores the rates, replacing any stored earlier for the same pair and date
+func (s *ExchangeRateDatabaseService) SaveExchangeRates(rates []models.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	err := s.database.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base"}, {Name: "quote"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source"}),
+	}).Create(&rates).Error
+	if err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
+
+// GetExchangeRate returns what one unit of from is worth in to, using the latest rate dated between
+// since and until. The rate may be stored as quoted, inverted, or as two rates against a common base.
+func (s *ExchangeRateDatabaseService) GetExchangeRate(from string, to string, since time.Time, until time.Time) (float64, bool, error) {
+	queries := []*gorm.DB{
+		s.database.Table("exchange_rates").
+			Select("rate").
+			Where("base = ? AND quote = ? AND date BETWEEN ? AND ?", from, to, since, until).
+			Order("date DESC"),
+		s.database.Table("exchange_rates").
+			Select("1 / rate").
+			Where("base = ? AND quote = ? AND date BETWEEN ? AND ?", to, from, since, until).
+			Order("date DESC"),
+		s.database.Table("exchange_rates AS f").
+			Select("t.rate / f.rate").
+			Joins("JOIN exchange_rates AS t ON t.base = f.base AND t.date = f.date").
+			Where("f.quote = ? AND t.quote = ? AND f.date BETWEEN ? AND ?", from, to, since, until).
+			Order("f.date DESC"),
+	}
+
+	for _, query := range queries {
+		var rates []float64
+		if err := query.Limit(1).Pluck("rate", &rates).Error; err != nil {
+			return 0, false, appErrors.NewDBError(err)
+		}
+		if len(rates) > 0 {
+			return rates[0], true, nil
+		}
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS users (
+    id                 UUID PRIMARY KEY,
+    email              TEXT NOT NULL,
+    name               VARCHAR(100),
+    email_verified     BOOLEAN DEFAULT FALSE,
+    email_verified_at  TIMESTAMPTZ,
+    password           TEXT NOT NULL,
+    two_factor_enabled BOOLEAN DEFAULT FALSE,
+    two_factor_secret  TEXT,
+    created_at         TIMESTAMPTZ
+);
+
+-- Schemas created by hand before these columns existed skip the CREATE above, so add whatever is
+-- missing. Users who signed up before email verification existed count as verified: the column is
+-- added with a TRUE default to backfill them, then new rows default to FALSE.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS name               VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS email_verified     BOOLEAN DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS email_verified_at  TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS two_factor_enabled BOOLEAN DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS two_factor_secret  TEXT,
+    ADD COLUMN IF NOT EXISTS created_at         TIMESTAMPTZ;
+
+ALTER TABLE users ALTER COLUMN email_verified SET DEFAULT FALSE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+-- GetUserByEmail compares case-insensitively, so uniqueness has to as well
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email));
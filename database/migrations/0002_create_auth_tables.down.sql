@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS email_verification_tokens;
+DROP TABLE IF EXISTS email_change_tokens;
+DROP TABLE IF EXISTS recovery_codes;
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,103 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id         UUID PRIMARY KEY,
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token      UUID NOT NULL,
+    user_agent TEXT,
+    ip_address VARCHAR(45),
+    revoked    BOOLEAN DEFAULT FALSE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- Hand-made schemas skip the CREATE TABLEs in this file, so each is followed by the columns the
+-- models need that such a table may be missing
+ALTER TABLE sessions
+    ADD COLUMN IF NOT EXISTS user_agent TEXT,
+    ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45),
+    ADD COLUMN IF NOT EXISTS revoked    BOOLEAN DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_token ON sessions (token);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         UUID PRIMARY KEY,
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    session_id UUID NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+    token      UUID NOT NULL,
+    family_id  UUID NOT NULL,
+    parent_id  UUID REFERENCES refresh_tokens (id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked    BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE refresh_tokens
+    ADD COLUMN IF NOT EXISTS family_id UUID,
+    ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES refresh_tokens (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS revoked   BOOLEAN DEFAULT FALSE;
+
+-- Tokens issued before rotation tracking each start their own family
+UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL;
+
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens (session_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token ON refresh_tokens (token);
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id         UUID PRIMARY KEY,
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token      TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used       BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE password_reset_tokens ADD COLUMN IF NOT EXISTS used BOOLEAN DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_password_reset_tokens_token ON password_reset_tokens (token);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id         UUID PRIMARY KEY,
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  TEXT NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE recovery_codes ADD COLUMN IF NOT EXISTS used_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id);
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_code_hash ON recovery_codes (code_hash);
+
+CREATE TABLE IF NOT EXISTS email_change_tokens (
+    id         UUID PRIMARY KEY,
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    new_email  TEXT NOT NULL,
+    token      TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used       BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE email_change_tokens ADD COLUMN IF NOT EXISTS used BOOLEAN DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_email_change_tokens_user_id ON email_change_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_change_tokens_token ON email_change_tokens (token);
+
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    id         UUID PRIMARY KEY,
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token      TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used       BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE email_verification_tokens ADD COLUMN IF NOT EXISTS used BOOLEAN DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_id ON email_verification_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_verification_tokens_token ON email_verification_tokens (token);
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id         UUID PRIMARY KEY,
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       VARCHAR(100) NOT NULL,
+    type       VARCHAR(10) NOT NULL CHECK (type IN ('expense', 'income')),
+    icon       VARCHAR(50) DEFAULT NULL,
+    is_default BOOLEAN DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_categories_user_id ON categories (user_id);
+
+CREATE TABLE IF NOT EXISTS budgets (
+    id         UUID PRIMARY KEY,
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type       VARCHAR(10) NOT NULL CHECK (type IN ('week', 'month', 'year')),
+    name       VARCHAR(255) NOT NULL,
+    start_date TIMESTAMP NOT NULL,
+    end_date   TIMESTAMP NOT NULL,
+    amount     DECIMAL(12, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_budgets_user_id ON budgets (user_id);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id          UUID PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    amount      DECIMAL NOT NULL,
+    type        VARCHAR(10) NOT NULL CHECK (type IN ('expense', 'income')),
+    name        VARCHAR(255) NOT NULL,
+    date        TIMESTAMPTZ NOT NULL,
+    note        TEXT,
+    budget_id   UUID,
+    category_id UUID,
+    created_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions (user_id);
+-- Every listing and report filters a user's transactions by date
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id_date ON transactions (user_id, date);
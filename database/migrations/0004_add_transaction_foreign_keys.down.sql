@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_transactions_budget_id;
+DROP INDEX IF EXISTS idx_transactions_category_id;
+
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS fk_transactions_budget,
+    DROP CONSTRAINT IF EXISTS fk_transactions_category;
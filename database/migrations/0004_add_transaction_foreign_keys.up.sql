@@ -0,0 +1,23 @@
+-- Older rows may point at deleted categories and budgets or at ones owned by another user
+UPDATE transactions t
+SET category_id = NULL
+WHERE category_id IS NOT NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM categories c WHERE c.id = t.category_id AND c.user_id = t.user_id
+  );
+
+UPDATE transactions t
+SET budget_id = NULL
+WHERE budget_id IS NOT NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM budgets b WHERE b.id = t.budget_id AND b.user_id = t.user_id
+  );
+
+-- The services decide what happens to referencing transactions before a delete, the keys only
+-- make sure nothing is left dangling
+ALTER TABLE transactions
+    ADD CONSTRAINT fk_transactions_category FOREIGN KEY (category_id) REFERENCES categories (id),
+    ADD CONSTRAINT fk_transactions_budget FOREIGN KEY (budget_id) REFERENCES budgets (id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_category_id ON transactions (category_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_budget_id ON transactions (budget_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_categories_user_id_name_id;
+DROP INDEX IF EXISTS idx_budgets_user_id_start_date_id;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id_date ON transactions (user_id, date);
+DROP INDEX IF EXISTS idx_transactions_user_id_date_id;
@@ -0,0 +1,6 @@
+-- Keyset pagination orders by the sort column and then id, so the indexes carry id as a tie breaker
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id_date_id ON transactions (user_id, date, id);
+DROP INDEX IF EXISTS idx_transactions_user_id_date;
+
+CREATE INDEX IF NOT EXISTS idx_budgets_user_id_start_date_id ON budgets (user_id, start_date, id);
+CREATE INDEX IF NOT EXISTS idx_categories_user_id_name_id ON categories (user_id, name, id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_transactions_note_trgm;
+DROP INDEX IF EXISTS idx_transactions_name_trgm;
+DROP INDEX IF EXISTS idx_transactions_search_vector;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS search_vector;
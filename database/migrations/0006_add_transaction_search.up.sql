@@ -0,0 +1,14 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Names weigh more than notes when ranking matches
+ALTER TABLE transactions
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(note, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_search_vector ON transactions USING GIN (search_vector);
+
+-- Trigram indexes serve substring (ILIKE) and fuzzy (<%) matches
+CREATE INDEX IF NOT EXISTS idx_transactions_name_trgm ON transactions USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_transactions_note_trgm ON transactions USING GIN (note gin_trgm_ops);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_transactions_recurring_transaction_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS recurring_transaction_id;
+
+DROP TABLE IF EXISTS recurring_transaction_exceptions;
+DROP TABLE IF EXISTS recurring_transactions;
@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS recurring_transactions (
+    id                 UUID PRIMARY KEY,
+    user_id            UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name               VARCHAR(255) NOT NULL,
+    amount             DECIMAL NOT NULL,
+    type               VARCHAR(10) NOT NULL CHECK (type IN ('expense', 'income')),
+    note               TEXT,
+    category_id        UUID REFERENCES categories (id) ON DELETE SET NULL,
+    budget_id          UUID REFERENCES budgets (id) ON DELETE SET NULL,
+    frequency          VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly')),
+    repeat_interval    INTEGER NOT NULL DEFAULT 1 CHECK (repeat_interval > 0),
+    start_date         TIMESTAMPTZ NOT NULL,
+    end_date           TIMESTAMPTZ,
+    count              INTEGER CHECK (count > 0),
+    occurrence_index   INTEGER NOT NULL DEFAULT 0,
+    next_occurrence_at TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ NOT NULL,
+    updated_at         TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_transactions_user_id ON recurring_transactions (user_id);
+-- The scheduler polls for templates that are due
+CREATE INDEX IF NOT EXISTS idx_recurring_transactions_next_occurrence_at
+    ON recurring_transactions (next_occurrence_at) WHERE next_occurrence_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS recurring_transaction_exceptions (
+    id                       UUID PRIMARY KEY,
+    recurring_transaction_id UUID NOT NULL REFERENCES recurring_transactions (id) ON DELETE CASCADE,
+    occurrence_date          TIMESTAMPTZ NOT NULL,
+    skip                     BOOLEAN NOT NULL DEFAULT FALSE,
+    name                     VARCHAR(255),
+    amount                   DECIMAL,
+    note                     TEXT,
+    date                     TIMESTAMPTZ,
+    created_at               TIMESTAMPTZ NOT NULL,
+    CONSTRAINT idx_recurring_exception_occurrence UNIQUE (recurring_transaction_id, occurrence_date)
+);
+
+ALTER TABLE transactions
+    ADD COLUMN recurring_transaction_id UUID REFERENCES recurring_transactions (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_recurring_transaction_id ON transactions (recurring_transaction_id);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS budget_periods;
+
+DROP INDEX IF EXISTS idx_budgets_recurring_end_date;
+
+ALTER TABLE budgets
+    DROP COLUMN IF EXISTS anchor_end_date,
+    DROP COLUMN IF EXISTS anchor_start_date,
+    DROP COLUMN IF EXISTS period_index,
+    DROP COLUMN IF EXISTS carried_over,
+    DROP COLUMN IF EXISTS carry_over,
+    DROP COLUMN IF EXISTS recurring;
@@ -0,0 +1,34 @@
+ALTER TABLE budgets
+    ADD COLUMN IF NOT EXISTS recurring         BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS carry_over        VARCHAR(10) NOT NULL DEFAULT 'none'
+        CHECK (carry_over IN ('none', 'unspent', 'overspent', 'all')),
+    ADD COLUMN IF NOT EXISTS carried_over      DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS period_index      INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS anchor_start_date TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS anchor_end_date   TIMESTAMP;
+
+-- Existing budgets become the first period of their series
+UPDATE budgets SET anchor_start_date = start_date, anchor_end_date = end_date WHERE anchor_start_date IS NULL;
+
+ALTER TABLE budgets
+    ALTER COLUMN anchor_start_date SET NOT NULL,
+    ALTER COLUMN anchor_end_date SET NOT NULL;
+
+-- The rollover job polls for recurring budgets whose period has ended
+CREATE INDEX IF NOT EXISTS idx_budgets_recurring_end_date ON budgets (end_date) WHERE recurring;
+
+CREATE TABLE IF NOT EXISTS budget_periods (
+    id           UUID PRIMARY KEY,
+    budget_id    UUID NOT NULL REFERENCES budgets (id) ON DELETE CASCADE,
+    start_date   TIMESTAMP NOT NULL,
+    end_date     TIMESTAMP NOT NULL,
+    amount       DECIMAL(12, 2) NOT NULL,
+    carried_over DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    created_at   TIMESTAMP NOT NULL,
+    CONSTRAINT idx_budget_periods_budget_start UNIQUE (budget_id, start_date)
+);
+
+INSERT INTO budget_periods (id, budget_id, start_date, end_date, amount, carried_over, created_at)
+SELECT gen_random_uuid(), id, start_date, end_date, amount, 0, created_at
+FROM budgets
+ON CONFLICT DO NOTHING;
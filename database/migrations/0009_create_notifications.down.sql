@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS notifications;
+
+ALTER TABLE budgets DROP COLUMN IF EXISTS alert_thresholds;
@@ -0,0 +1,19 @@
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS alert_thresholds JSONB NOT NULL DEFAULT '[80, 100]';
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id           UUID PRIMARY KEY,
+    user_id      UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type         VARCHAR(50) NOT NULL,
+    title        VARCHAR(255) NOT NULL,
+    message      TEXT NOT NULL,
+    budget_id    UUID REFERENCES budgets (id) ON DELETE CASCADE,
+    threshold    INTEGER,
+    period_start TIMESTAMP,
+    dedup_key    VARCHAR(255) NOT NULL,
+    read_at      TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL,
+    CONSTRAINT idx_notifications_user_dedup_key UNIQUE (user_id, dedup_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created_at ON notifications (user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications (user_id) WHERE read_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_user_category_date;
+
+DROP TABLE IF EXISTS budget_categories;
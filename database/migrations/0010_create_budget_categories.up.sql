@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS budget_categories (
+    budget_id   UUID NOT NULL REFERENCES budgets (id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    PRIMARY KEY (budget_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_categories_category_id ON budget_categories (category_id);
+
+-- Category budgets look up a user's expenses by category and date
+CREATE INDEX IF NOT EXISTS idx_transactions_user_category_date ON transactions (user_id, category_id, date);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS transaction_splits (
+    id             UUID PRIMARY KEY,
+    transaction_id UUID NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    category_id    UUID REFERENCES categories (id) ON DELETE SET NULL,
+    amount         DECIMAL NOT NULL CHECK (amount > 0),
+    note           TEXT,
+    created_at     TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_transaction_id ON transaction_splits (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_category_id ON transaction_splits (category_id);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id         UUID PRIMARY KEY,
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       VARCHAR(50) NOT NULL,
+    color      VARCHAR(7),
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- Tag names are unique per user regardless of case
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_name ON tags (user_id, LOWER(name));
+
+CREATE TABLE IF NOT EXISTS transaction_tags (
+    transaction_id UUID NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    tag_id         UUID NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (transaction_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_tags_tag_id ON transaction_tags (tag_id);
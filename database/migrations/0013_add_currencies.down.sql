@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE recurring_transactions DROP COLUMN IF EXISTS currency;
+ALTER TABLE budgets DROP COLUMN IF EXISTS currency;
+ALTER TABLE transactions DROP COLUMN IF EXISTS currency;
+ALTER TABLE users DROP COLUMN IF EXISTS base_currency;
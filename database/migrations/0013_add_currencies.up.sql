@@ -0,0 +1,19 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS base_currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Existing amounts are taken to be in their owner's base currency
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE recurring_transactions ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base       VARCHAR(3) NOT NULL,
+    quote      VARCHAR(3) NOT NULL,
+    date       DATE NOT NULL,
+    rate       DOUBLE PRECISION NOT NULL CHECK (rate > 0),
+    source     VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (base, quote, date)
+);
+
+-- Cross rates join two rates of the same base and date by their quote currencies
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_quote_date ON exchange_rates (quote, date);
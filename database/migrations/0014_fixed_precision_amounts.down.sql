@@ -0,0 +1,4 @@
+ALTER TABLE recurring_transaction_exceptions ALTER COLUMN amount TYPE DECIMAL;
+ALTER TABLE recurring_transactions ALTER COLUMN amount TYPE DECIMAL;
+ALTER TABLE transaction_splits ALTER COLUMN amount TYPE DECIMAL;
+ALTER TABLE transactions ALTER COLUMN amount TYPE DECIMAL;
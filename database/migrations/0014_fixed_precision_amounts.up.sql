@@ -0,0 +1,6 @@
+-- Amounts are exact to the cent, the same precision budgets already use. Stray extra decimals left
+-- by float arithmetic are rounded away.
+ALTER TABLE transactions ALTER COLUMN amount TYPE DECIMAL(12, 2) USING ROUND(amount, 2);
+ALTER TABLE transaction_splits ALTER COLUMN amount TYPE DECIMAL(12, 2) USING ROUND(amount, 2);
+ALTER TABLE recurring_transactions ALTER COLUMN amount TYPE DECIMAL(12, 2) USING ROUND(amount, 2);
+ALTER TABLE recurring_transaction_exceptions ALTER COLUMN amount TYPE DECIMAL(12, 2) USING ROUND(amount, 2);
@@ -0,0 +1,4 @@
+-- Reports and budget periods follow the user's calendar. Existing users keep the UTC boundaries
+-- they had, with weeks starting on Monday (0 is Sunday).
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS week_start SMALLINT NOT NULL DEFAULT 1 CHECK (week_start BETWEEN 0 AND 6);
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"time"
+
+	appErrors "github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationDatabaseServiceInterface interface {
+	CreateNotification(n *models.Notification) (bool, error)
+	GetNotificationsByUser(userID uuid.UUID, unreadOnly bool, page PageQuery) ([]models.Notification, error)
+	MarkNotificationRead(id uuid.UUID, userID uuid.UUID, readAt time.Time) (bool, error)
+	MarkAllNotificationsRead(userID uuid.UUID, readAt time.Time) error
+}
+
+type NotificationDatabaseService struct {
+	database *gorm.DB
+}
+
+func NewNotificationDatabaseService(db *gorm.DB) NotificationDatabaseServiceInterface {
+	return &NotificationDatabaseService{database: db}
+}
+
+// CreateNotification stores the notification unless the user already has one with the same
+// dedup key, and reports whether it was stored
+func (s *NotificationDatabaseService) CreateNotification(n *models.Notification) (bool, error) {
+	result := s.database.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "dedup_key"}},
+		DoNothing: true,
+	}).Create(n)
+	if result.Error != nil {
+		return false, appErrors.NewDBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (s *NotificationDatabaseService) GetNotificationsByUser(userID uuid.UUID, unreadOnly bool, page PageQuery) ([]models.Notification, error) {
+	query := s.database.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []models.Notification
+	if err := page.apply(query).Find(&notifications).Error; err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return notifications, nil
+}
+
+// MarkNotificationRead reports false when the user has no such notification. Reading it again keeps the first read time.
+func (s *NotificationDatabaseService) MarkNotificationRead(id uuid.UUID, userID uuid.UUID, readAt time.Time) (bool, error) {
+	var count int64
+	if err := s.database.Model(&models.Notification{}).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error; err != nil {
+		return false, appErrors.NewDBError(err)
+	}
+	if count == 0 {
+		return false, nil
+	}
+
+	err := s.database.Model(&models.Notification{}).
+		Where("id = ? AND read_at IS NULL", id).
+		Update("read_at", readAt).Error
+	if err != nil {
+		return false, appErrors.NewDBError(err)
+	}
+	return true, nil
+}
+
+func (s *NotificationDatabaseService) MarkAllNotificationsRead(userID uuid.UUID, readAt time.Time) error {
+	err := s.database.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", readAt).Error
+	if err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
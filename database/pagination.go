@@ -0,0 +1,48 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PageQuery selects one page of a keyset paginated list. Rows are ordered by Column and then by
+// id in the same direction, so the last row of a page pins down where the next one starts.
+// The zero value applies no ordering or limit.
+type PageQuery struct {
+	Column     string
+	Descending bool
+	// Limit is the page size. One extra row is fetched so callers can tell whether another page follows.
+	Limit int
+	After *PageCursor
+}
+
+// PageCursor is the sort key of the last row of the previous page
+type PageCursor struct {
+	Value any
+	ID    uuid.UUID
+}
+
+// apply adds the cursor condition, ordering and limit to query. Column always comes from a fixed
+// list of sortable columns, never from user input.
+func (p PageQuery) apply(query *gorm.DB) *gorm.DB {
+	if p.Column == "" {
+		return query
+	}
+
+	operator, direction := ">", "ASC"
+	if p.Descending {
+		operator, direction = "<", "DESC"
+	}
+
+	if p.After != nil {
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", p.Column, operator), p.After.Value, p.After.ID)
+	}
+
+	query = query.Order(fmt.Sprintf("%s %s, id %s", p.Column, direction, direction))
+	if p.Limit > 0 {
+		query = query.Limit(p.Limit + 1)
+	}
+	return query
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	appErrors "github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PasswordResetTokenDatabaseServiceInterface interface {
+	CreatePasswordResetToken(token *models.PasswordResetToken) error
+	GetPasswordResetTokenByToken(tokenHash string) (*models.PasswordResetToken, error)
+	MarkPasswordResetTokenUsed(tokenID uuid.UUID) (bool, error)
+	InvalidatePasswordResetTokensByUserID(userID uuid.UUID) error
+}
+
+type PasswordResetTokenDatabaseService struct {
+	database *gorm.DB
+}
+
+func NewPasswordResetTokenDatabaseService(db *gorm.DB) PasswordResetTokenDatabaseServiceInterface {
+	return &PasswordResetTokenDatabaseService{database: db}
+}
+
+func (s *PasswordResetTokenDatabaseService) CreatePasswordResetToken(token *models.PasswordResetToken) error {
+	if err := s.database.Create(token).Error; err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
+
+func (s *PasswordResetTokenDatabaseService) GetPasswordResetTokenByToken(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := s.database.First(&token, "token = ?", tokenHash).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return &token, nil
+}
+
+// MarkPasswordResetTokenUsed flips the token to used only if it is still unused and unexpired.
+// It reports false when another request consumed the token first.
+func (s *PasswordResetTokenDatabaseService) MarkPasswordResetTokenUsed(tokenID uuid.UUID) (bool, error) {
+	result := s.database.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used = false AND expires_at > ?", tokenID, time.Now()).
+		Update("used", true)
+	if result.Error != nil {
+		return false, appErrors.NewDBError(result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// InvalidatePasswordResetTokensByUserID marks every outstanding token of the user as used
+func (s *PasswordResetTokenDatabaseService) InvalidatePasswordResetTokensByUserID(userID uuid.UUID) error {
+	err := s.database.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used = false", userID).
+		Update("used", true).Error
+	if err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
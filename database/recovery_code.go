@@ -0,0 +1,72 @@
+package database
+
+import (
+	"time"
+
+	appErrors "github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeDatabaseServiceInterface interface {
+	ReplaceRecoveryCodes(userID uuid.UUID, codes []models.RecoveryCode) error
+	ConsumeRecoveryCode(userID uuid.UUID, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(userID uuid.UUID) (int64, error)
+	DeleteRecoveryCodesByUserID(userID uuid.UUID) error
+}
+
+type RecoveryCodeDatabaseService struct {
+	database *gorm.DB
+}
+
+func NewRecoveryCodeDatabaseService(db *gorm.DB) RecoveryCodeDatabaseServiceInterface {
+	return &RecoveryCodeDatabaseService{database: db}
+}
+
+// ReplaceRecoveryCodes deletes every existing code of the user and stores the new set in one transaction
+func (s *RecoveryCodeDatabaseService) ReplaceRecoveryCodes(userID uuid.UUID, codes []models.RecoveryCode) error {
+	err := s.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.RecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
+
+// ConsumeRecoveryCode marks a matching unused code as used in a single conditional update,
+// so two concurrent logins cannot spend the same code. It reports whether a code was consumed.
+func (s *RecoveryCodeDatabaseService) ConsumeRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	result := s.database.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, appErrors.NewDBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (s *RecoveryCodeDatabaseService) CountUnusedRecoveryCodes(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := s.database.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	if err != nil {
+		return 0, appErrors.NewDBError(err)
+	}
+	return count, nil
+}
+
+func (s *RecoveryCodeDatabaseService) DeleteRecoveryCodesByUserID(userID uuid.UUID) error {
+	if err := s.database.Delete(&models.RecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	appErrors "github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RecurringTransactionDatabaseServiceInterface interface {
+	CreateRecurringTransaction(rt *models.RecurringTransaction) error
+	GetRecurringTransactionByID(id uuid.UUID, userId uuid.UUID) (*models.RecurringTransaction, error)
+	GetRecurringTransactionsByUser(userID uuid.UUID, page PageQuery) ([]models.RecurringTransaction, error)
+	GetDueRecurringTransactions(now time.Time, limit int) ([]models.RecurringTransaction, error)
+	UpdateRecurringTransaction(id uuid.UUID, updates map[string]any) error
+	DeleteRecurringTransaction(id uuid.UUID) error
+	GetExceptions(recurringTransactionID uuid.UUID) ([]models.RecurringTransactionException, error)
+	UpsertException(exception *models.RecurringTransactionException) error
+	DeleteException(recurringTransactionID uuid.UUID, occurrenceDate time.Time) (bool, error)
+	DeleteExceptions(recurringTransactionID uuid.UUID) error
+}
+
+type RecurringTransactionDatabaseService struct {
+	database *gorm.DB
+}
+
+func NewRecurringTransactionDatabaseService(db *gorm.DB) RecurringTransactionDatabaseServiceInterface {
+	return &RecurringTransactionDatabaseService{database: db}
+}
+
+func (s *RecurringTransactionDatabaseService) CreateRecurringTransaction(rt *models.RecurringTransaction) error {
+	if err := s.database.Create(rt).Error; err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
+
+func (s *RecurringTransactionDatabaseService) GetRecurringTransactionByID(id uuid.UUID, userId uuid.UUID) (*models.RecurringTransaction, error) {
+	var rt models.RecurringTransaction
+	err := s.database.First(&rt, "id = ? AND user_id = ?", id, userId).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return &rt, nil
+}
+
+func (s *RecurringTransactionDatabaseService) GetRecurringTransactionsByUser(userID uuid.UUID, page PageQuery) ([]models.RecurringTransaction, error) {
+	var rts []models.RecurringTransaction
+	err := page.apply(s.database.Where("user_id = ?", userID)).Find(&rts).Error
+	if err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return rts, nil
+}
+
+// GetDueRecurringTransactions returns up to limit templates with an occurrence at or before now, oldest first
+func (s *RecurringTransactionDatabaseService) GetDueRecurringTransactions(now time.Time, limit int) ([]models.RecurringTransaction, error) {
+	var rts []models.RecurringTransaction
+	err := s.database.
+		Where("next_occurrence_at IS NOT NULL AND next_occurrence_at <= ?", now).
+		Order("next_occurrence_at").
+		Limit(limit).
+		Find(&rts).Error
+	if err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return rts, nil
+}
+
+func (s *RecurringTransactionDatabaseService) UpdateRecurringTransaction(id uuid.UUID, updates map[string]any) error {
+	if err := s.database.Model(&models.RecurringTransaction{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
+
+// DeleteRecurringTransaction removes the template and its exceptions. Transactions it already
+// recorded are kept.
+func (s *RecurringTransactionDatabaseService) DeleteRecurringTransaction(id uuid.UUID) error {
+	err := s.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.RecurringTransactionException{}, "recurring_transaction_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.RecurringTransaction{}, "id = ?", id).Error
+	})
+	if err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
+
+func (s *RecurringTransactionDatabaseService) GetExceptions(recurringTransactionID uuid.UUID) ([]models.RecurringTransactionException, error) {
+	var exceptions []models.RecurringTransactionException
+	err := s.database.Where("recurring_transaction_id = ?", recurringTransactionID).Order("occurrence_date").Find(&exceptions).Error
+	if err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return exceptions, nil
+}
+
+// UpsertException stores the exception, replacing any earlier one for the same occurrence
+func (s *RecurringTransactionDatabaseService) UpsertException(exception *models.RecurringTransactionException) error {
+	err := s.database.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "recurring_transaction_id"}, {Name: "occurrence_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"skip", "name", "amount", "note", "date"}),
+	}).Create(exception).Error
+	if err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
+
+// DeleteException removes the exception for one occurrence and reports whether there was one
+func (s *RecurringTransactionDatabaseService) DeleteException(recurringTransactionID uuid.UUID, occurrenceDate time.Time) (bool, error) {
+	result := s.database.Delete(&models.RecurringTransactionException{}, "recurring_transaction_id = ? AND occurrence_date = ?", recurringTransactionID, occurrenceDate)
+	if result.Error != nil {
+		return false, appErrors.NewDBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (s *RecurringTransactionDatabaseService) DeleteExceptions(recurringTransactionID uuid.UUID) error {
+	if err := s.database.Delete(&models.RecurringTransactionException{}, "recurring_transaction_id = ?", recurringTransactionID).Error; err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
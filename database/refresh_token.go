@@ -11,6 +11,10 @@ type RefreshTokenDatabaseServiceInterface interface {
 	GetRefreshTokenByToken(token uuid.UUID) (*models.RefreshToken, error)
 	RevokeRefreshToken(tokenID uuid.UUID) error
 	RevokeRefreshTokensBySessionID(sessionID uuid.UUID) error
+	RevokeRefreshTokensByUserID(userID uuid.UUID) error
+	RevokeRefreshTokenIfActive(tokenID uuid.UUID) (bool, error)
+	GetRefreshTokensByFamilyID(familyID uuid.UUID) ([]models.RefreshToken, error)
+	RevokeRefreshTokensByFamilyID(familyID uuid.UUID) error
 	DeleteRefreshToken(tokenID uuid.UUID) error
 }
 
@@ -39,6 +43,30 @@ func (s *RefreshTokenDatabaseService) RevokeRefreshTokensBySessionID(sessionID u
 	return s.database.Model(&models.RefreshToken{}).Where("session_id = ?", sessionID).Update("revoked", true).Error
 }
 
+func (s *RefreshTokenDatabaseService) RevokeRefreshTokensByUserID(userID uuid.UUID) error {
+	return s.database.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked = false", userID).Update("revoked", true).Error
+}
+
+// RevokeRefreshTokenIfActive revokes the token only if nobody else has revoked it yet.
+// It reports false when the token was already revoked, which means it is being replayed.
+func (s *RefreshTokenDatabaseService) RevokeRefreshTokenIfActive(tokenID uuid.UUID) (bool, error) {
+	result := s.database.Model(&models.RefreshToken{}).Where("id = ? AND revoked = false", tokenID).Update("revoked", true)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (s *RefreshTokenDatabaseService) GetRefreshTokensByFamilyID(familyID uuid.UUID) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	err := s.database.Where("family_id = ?", familyID).Find(&tokens).Error
+	return tokens, err
+}
+
+func (s *RefreshTokenDatabaseService) RevokeRefreshTokensByFamilyID(familyID uuid.UUID) error {
+	return s.database.Model(&models.RefreshToken{}).Where("family_id = ? AND revoked = false", familyID).Update("revoked", true).Error
+}
+
 func (s *RefreshTokenDatabaseService) DeleteRefreshToken(tokenID uuid.UUID) error {
 	return s.database.Delete(&models.RefreshToken{}, "id = ?", tokenID).Error
 }
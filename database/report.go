@@ -0,0 +1,165 @@
+package database
+
+import (
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models/reports"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReportDatabaseServiceInterface sums transactions in the database for reports, so that a report
+// reads a handful of totals however many transactions it covers. Every method takes the currency
+// the report is in: totals in other currencies come back per day for conversion.
+type ReportDatabaseServiceInterface interface {
+	SumByType(userID uuid.UUID, currency string, startDate, endDate time.Time) ([]reports.ReportTotal, error)
+	SumByCategory(userID uuid.UUID, currency string, categoryID *uuid.UUID, transactionType string) ([]reports.ReportTotal, error)
+	SumByTag(userID uuid.UUID, currency string) ([]reports.ReportTotal, error)
+	SumByBudgetPeriod(userID uuid.UUID, budgetID uuid.UUID, currency string, trackedFrom, trackedTo time.Time) ([]reports.ReportTotal, error)
+	SumByBucket(userID uuid.UUID, currency string, interval string, timezone string, weekStart time.Weekday, startDate, endDate time.Time, groupBy string) ([]reports.ReportTotal, error)
+}
+
+type ReportDatabaseService struct {
+	database *gorm.DB
+}
+
+func NewReportDatabaseService(db *gorm.DB) ReportDatabaseServiceInterface {
+	return &ReportDatabaseService{database: db}
+}
+
+// foreignDay is the day of a transaction in another currency than the report's, and NULL for the
+// rest so those are summed in one go
+const foreignDay = "CASE WHEN transactions.currency = ? THEN NULL ELSE date_trunc('day', transactions.date AT TIME ZONE 'UTC') END AS day"
+
+// splitCategory is the category an amount counts towards: the split's for split transactions
+const splitCategory = "CASE WHEN transaction_splits.id IS NULL THEN transactions.category_id ELSE transaction_splits.category_id END"
+
+// SumByType totals the user's income and expenses from startDate until before endDate
+func (s *ReportDatabaseService) SumByType(userID uuid.UUID, currency string, startDate, endDate time.Time) ([]reports.ReportTotal, error) {
+	var totals []reports.ReportTotal
+	err := s.database.
+		Table("transactions").
+		Select("transactions.type, transactions.currency, "+foreignDay+", SUM(transactions.amount) AS amount", currency).
+		Where("transactions.user_id = ? AND transactions.date >= ? AND transactions.date < ?", userID, startDate, endDate).
+		Group("transactions.type, transactions.currency, day").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, errors.NewDBError(err)
+	}
+	return totals, nil
+}
+
+// SumByCategory totals the user's transactions per category with the category's name, counting
+// split transactions under their splits' categories. Uncategorized amounts have no GroupID. A nil
+// categoryID totals every category and an empty transactionType both types.
+func (s *ReportDatabaseService) SumByCategory(userID uuid.UUID, currency string, categoryID *uuid.UUID, transactionType string) ([]reports.ReportTotal, error) {
+	query := s.database.
+		Table("transactions").
+		Select(splitCategory+` AS group_id, categories.name AS group_name, transactions.type, transactions.currency, `+foreignDay+`,
+			SUM(COALESCE(transaction_splits.amount, transactions.amount)) AS amount`, currency).
+		Joins("LEFT JOIN transaction_splits ON transaction_splits.transaction_id = transactions.id").
+		Joins("LEFT JOIN categories ON categories.id = "+splitCategory).
+		Where("transactions.user_id = ?", userID)
+
+	if categoryID != nil {
+		query = query.Where(splitCategory+" = ?", *categoryID)
+	}
+	if transactionType != "" {
+		query = query.Where("transactions.type = ?", transactionType)
+	}
+
+	var totals []reports.ReportTotal
+	err := query.Group("group_id, categories.name, transactions.type, transactions.currency, day").Scan(&totals).Error
+	if err != nil {
+		return nil, errors.NewDBError(err)
+	}
+	return totals, nil
+}
+
+// SumByTag totals the user's transactions per tag. A transaction counts in full towards every tag
+// it has.
+func (s *ReportDatabaseService) SumByTag(userID uuid.UUID, currency string) ([]reports.ReportTotal, error) {
+	var totals []reports.ReportTotal
+	err := s.database.
+		Table("transaction_tags").
+		Select("transaction_tags.tag_id AS group_id, tags.name AS group_name, transactions.type, transactions.currency, "+foreignDay+", SUM(transactions.amount) AS amount", currency).
+		Joins("JOIN transactions ON transactions.id = transaction_tags.transaction_id").
+		Joins("JOIN tags ON tags.id = transaction_tags.tag_id").
+		Where("transactions.user_id = ?", userID).
+		Group("transaction_tags.tag_id, tags.name, transactions.type, transactions.currency, day").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, errors.NewDBError(err)
+	}
+	return totals, nil
+}
+
+// SumByBudgetPeriod totals the transactions that count towards a budget per budget period, with
+// the period's ID as GroupID. Those outside every period have no GroupID. Transactions naming the
+// budget always count in full; expenses in the categories it tracks, or their splits in them, only
+// between trackedFrom and trackedTo.
+func (s *ReportDatabaseService) SumByBudgetPeriod(userID uuid.UUID, budgetID uuid.UUID, currency string, trackedFrom, trackedTo time.Time) ([]reports.ReportTotal, error) {
+	var totals []reports.ReportTotal
+	err := s.database.
+		Table("transactions").
+		Select("period.id AS group_id, transactions.type, transactions.currency, "+foreignDay+", SUM(COALESCE(transaction_splits.amount, transactions.amount)) AS amount", currency).
+		Joins("LEFT JOIN transaction_splits ON transaction_splits.transaction_id = transactions.id").
+		// The newest period containing the transaction, so periods sharing a boundary don't count it twice
+		Joins(`LEFT JOIN LATERAL (
+			SELECT budget_periods.id FROM budget_periods
+			WHERE budget_periods.budget_id = ? AND transactions.date BETWEEN budget_periods.start_date AND budget_periods.end_date
+			ORDER BY budget_periods.start_date DESC LIMIT 1
+		) AS period ON true`, budgetID).
+		Where("transactions.user_id = ?", userID).
+		Where(`transactions.budget_id = ? OR (transactions.type = 'expense' AND transactions.date BETWEEN ? AND ?
+			AND `+splitCategory+` IN (SELECT category_id FROM budget_categories WHERE budget_id = ?))`, budgetID, trackedFrom, trackedTo, budgetID).
+		Group("period.id, transactions.type, transactions.currency, day").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, errors.NewDBError(err)
+	}
+	return totals, nil
+}
+
+// SumByBucket totals the user's transactions from startDate until before endDate per bucket of
+// the interval (day, week, month, quarter or year), with the bucket's start in the timezone as
+// Bucket. Weeks start on weekStart. groupBy "category" also splits buckets by category, counting split
+// transactions under their splits' categories, and "budget" by the budget transactions name.
+func (s *ReportDatabaseService) SumByBucket(userID uuid.UUID, currency string, interval string, timezone string, weekStart time.Weekday, startDate, endDate time.Time, groupBy string) ([]reports.ReportTotal, error) {
+	// date_trunc's weeks start on Monday, so other week starts shift dates forward and the bucket back
+	shift := 0
+	if interval == "week" {
+		shift = (int(time.Monday) - int(weekStart) + 7) % 7
+	}
+
+	columns := "date_trunc(?, (transactions.date AT TIME ZONE ?) + make_interval(days => ?)) - make_interval(days => ?) AS bucket, transactions.type, transactions.currency, " + foreignDay
+	amount := "SUM(transactions.amount) AS amount"
+	groups := "bucket, transactions.type, transactions.currency, day"
+
+	query := s.database.Table("transactions")
+	switch groupBy {
+	case "category":
+		columns += ", " + splitCategory + " AS group_id, categories.name AS group_name"
+		amount = "SUM(COALESCE(transaction_splits.amount, transactions.amount)) AS amount"
+		groups += ", group_id, categories.name"
+		query = query.
+			Joins("LEFT JOIN transaction_splits ON transaction_splits.transaction_id = transactions.id").
+			Joins("LEFT JOIN categories ON categories.id = " + splitCategory)
+	case "budget":
+		columns += ", transactions.budget_id AS group_id, budgets.name AS group_name"
+		groups += ", transactions.budget_id, budgets.name"
+		query = query.Joins("LEFT JOIN budgets ON budgets.id = transactions.budget_id")
+	}
+
+	var totals []reports.ReportTotal
+	err := query.
+		Select(columns+", "+amount, interval, timezone, shift, shift, currency).
+		Where("transactions.user_id = ? AND transactions.date >= ? AND transactions.date < ?", userID, startDate, endDate).
+		Group(groups).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, errors.NewDBError(err)
+	}
+	return totals, nil
+}
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"time"
+
 	"github.com/AlsoShantanuBorkar/budget_max/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -11,6 +13,9 @@ type SessionDatabaseServiceInterface interface {
 	GetSessionByToken(token uuid.UUID) (*models.Session, error)
 	DeleteSession(sessionID uuid.UUID) error
 	RevokeSession(tokenID uuid.UUID) error
+	RevokeSessionsByUserID(userID uuid.UUID) error
+	GetActiveSessionsByUserID(userID uuid.UUID) ([]models.Session, error)
+	GetSessionByID(sessionID uuid.UUID, userID uuid.UUID) (*models.Session, error)
 }
 
 type SessionDatabaseService struct {
@@ -41,3 +46,29 @@ func (s *SessionDatabaseService) DeleteSession(sessionID uuid.UUID) error {
 func (s *SessionDatabaseService) RevokeSession(tokenID uuid.UUID) error {
 	return s.database.Model(&models.Session{}).Where("id = ?", tokenID).Update("revoked", true).Error
 }
+
+func (s *SessionDatabaseService) RevokeSessionsByUserID(userID uuid.UUID) error {
+	return s.database.Model(&models.Session{}).Where("user_id = ? AND revoked = false", userID).Update("revoked", true).Error
+}
+
+// GetActiveSessionsByUserID returns the user's unrevoked, unexpired sessions, newest first
+func (s *SessionDatabaseService) GetActiveSessionsByUserID(userID uuid.UUID) ([]models.Session, error) {
+	var sessions []models.Session
+	err := s.database.
+		Where("user_id = ? AND revoked = false AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (s *SessionDatabaseService) GetSessionByID(sessionID uuid.UUID, userID uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	err := s.database.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"errors"
+
+	appErrors "github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TagDatabaseServiceInterface interface {
+	CreateTag(tag *models.Tag) error
+	GetTagByID(id uuid.UUID, userId uuid.UUID) (*models.Tag, error)
+	GetTagsByIDs(userID uuid.UUID, ids []uuid.UUID) ([]models.Tag, error)
+	GetUserTags(userID uuid.UUID, page PageQuery) ([]models.Tag, error)
+	UpdateTag(id uuid.UUID, updates map[string]any) error
+	DeleteTag(id uuid.UUID) error
+}
+
+type TagDatabaseService struct {
+	database *gorm.DB
+}
+
+func NewTagDatabaseService(db *gorm.DB) TagDatabaseServiceInterface {
+	return &TagDatabaseService{database: db}
+}
+
+func (s *TagDatabaseService) CreateTag(tag *models.Tag) error {
+	if err := s.database.Create(tag).Error; err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
+
+func (s *TagDatabaseService) GetTagByID(id uuid.UUID, userId uuid.UUID) (*models.Tag, error) {
+	var tag models.Tag
+	err := s.database.First(&tag, "id = ? AND user_id = ?", id, userId).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return &tag, nil
+}
+
+// GetTagsByIDs returns those of the given tags that belong to the user
+func (s *TagDatabaseService) GetTagsByIDs(userID uuid.UUID, ids []uuid.UUID) ([]models.Tag, error) {
+	var tags []models.Tag
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	err := s.database.Where("user_id = ? AND id IN ?", userID, ids).Order("name").Find(&tags).Error
+	if err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return tags, nil
+}
+
+func (s *TagDatabaseService) GetUserTags(userID uuid.UUID, page PageQuery) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := page.apply(s.database.Where("user_id = ?", userID)).Find(&tags).Error
+	if err != nil {
+		return nil, appErrors.NewDBError(err)
+	}
+	return tags, nil
+}
+
+func (s *TagDatabaseService) UpdateTag(id uuid.UUID, updates map[string]any) error {
+	if err := s.database.Model(&models.Tag{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
+
+// DeleteTag removes the tag. Its links to transactions are removed with it by the foreign key.
+func (s *TagDatabaseService) DeleteTag(id uuid.UUID) error {
+	if err := s.database.Delete(&models.Tag{}, "id = ?", id).Error; err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"strings"
 	"time"
 
 	"github.com/AlsoShantanuBorkar/budget_max/errors"
@@ -13,16 +14,28 @@ type TransactionDatabaseServiceInterface interface {
 	CreateTransaction(txn *models.Transaction) error
 	GetTransactionsByUser(userID uuid.UUID) ([]*models.Transaction, error)
 	UpdateTransaction(id uuid.UUID, updates map[string]any) error
+	UpdateTransactionAndSplits(id uuid.UUID, updates map[string]any, splits []models.TransactionSplit) error
+	SetTransactionTags(id uuid.UUID, tagIDs []uuid.UUID) error
 	DeleteTransaction(id uuid.UUID) error
 	GetTransactionByID(txnId uuid.UUID, userId uuid.UUID) (*models.Transaction, error)
 	GetTransactionsByBudget(userID uuid.UUID, budgetID uuid.UUID) ([]*models.Transaction, error)
+	GetTransactionsByBudgetAndDateRange(userID uuid.UUID, budgetID uuid.UUID, startDate, endDate time.Time) ([]*models.Transaction, error)
 	GetTransactionsByCategory(userID uuid.UUID, categoryID uuid.UUID) ([]*models.Transaction, error)
 	GetTransactionsByDateRange(userID uuid.UUID, startDate, endDate time.Time) ([]*models.Transaction, error)
 	GetTransactionsByType(userID uuid.UUID, transactionType string) ([]*models.Transaction, error)
-	GetTransactionsByAmountRange(userID uuid.UUID, minAmount, maxAmount float64) ([]*models.Transaction, error)
-	GetTransactionsWithFilters(userID uuid.UUID, filters map[string]interface{}) ([]*models.Transaction, error)
+	GetTransactionsByAmountRange(userID uuid.UUID, minAmount, maxAmount models.Money) ([]*models.Transaction, error)
+	GetTransactionsWithFilters(userID uuid.UUID, filters map[string]interface{}, page PageQuery) ([]*models.Transaction, error)
 }
 
+// searchMatch matches whole words through the full text index, substrings and prefixes through
+// the trigram indexes, and misspellings through trigram word similarity
+const searchMatch = `(search_vector @@ websearch_to_tsquery('english', ?)
+	OR name ILIKE ? OR note ILIKE ?
+	OR ? <% name)`
+
+// searchRank scores full text matches and adds how closely the query resembles a word in the name
+const searchRank = `(ts_rank(search_vector, websearch_to_tsquery('english', ?)) + word_similarity(?, name))::float8`
+
 type TransactionDatabaseService struct {
 	database *gorm.DB
 }
@@ -31,8 +44,10 @@ func NewTransactionDatabaseService(db *gorm.DB) TransactionDatabaseServiceInterf
 	return &TransactionDatabaseService{database: db}
 }
 
+// CreateTransaction stores the transaction with its splits and links to its tags. The tags
+// themselves must already exist.
 func (s *TransactionDatabaseService) CreateTransaction(txn *models.Transaction) error {
-	if err := s.database.Create(txn).Error; err != nil {
+	if err := s.database.Omit("Tags.*").Create(txn).Error; err != nil {
 		return errors.NewDBError(err)
 	}
 	return nil
@@ -54,6 +69,49 @@ func (s *TransactionDatabaseService) UpdateTransaction(id uuid.UUID, updates map
 	return nil
 }
 
+// UpdateTransactionAndSplits applies updates and replaces the transaction's splits in one transaction
+func (s *TransactionDatabaseService) UpdateTransactionAndSplits(id uuid.UUID, updates map[string]any, splits []models.TransactionSplit) error {
+	err := s.database.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&models.Transaction{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&models.TransactionSplit{}, "transaction_id = ?", id).Error; err != nil {
+			return err
+		}
+		if len(splits) == 0 {
+			return nil
+		}
+		return tx.Create(&splits).Error
+	})
+	if err != nil {
+		return errors.NewDBError(err)
+	}
+	return nil
+}
+
+// SetTransactionTags replaces the tags of the transaction
+func (s *TransactionDatabaseService) SetTransactionTags(id uuid.UUID, tagIDs []uuid.UUID) error {
+	err := s.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.TransactionTag{}, "transaction_id = ?", id).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) == 0 {
+			return nil
+		}
+		links := make([]models.TransactionTag, len(tagIDs))
+		for i, tagID := range tagIDs {
+			links[i] = models.TransactionTag{TransactionID: id, TagID: tagID}
+		}
+		return tx.Create(&links).Error
+	})
+	if err != nil {
+		return errors.NewDBError(err)
+	}
+	return nil
+}
+
 func (s *TransactionDatabaseService) DeleteTransaction(id uuid.UUID) error {
 	if err := s.database.Delete(&models.Transaction{}, "id = ?", id).Error; err != nil {
 		return errors.NewDBError(err)
@@ -63,7 +121,7 @@ func (s *TransactionDatabaseService) DeleteTransaction(id uuid.UUID) error {
 
 func (s *TransactionDatabaseService) GetTransactionByID(txnId uuid.UUID, userId uuid.UUID) (*models.Transaction, error) {
 	var txn models.Transaction
-	err := s.database.First(&txn, "id = ? AND user_id = ?", txnId, userId).Error
+	err := s.database.Preload("Splits").Preload("Tags").First(&txn, "id = ? AND user_id = ?", txnId, userId).Error
 	if err != nil {
 		return nil, errors.NewDBError(err)
 	}
@@ -80,10 +138,30 @@ func (s *TransactionDatabaseService) GetTransactionsByBudget(userID uuid.UUID, b
 	return txns, nil
 }
 
-// GetTransactionsByCategory returns all transactions for a specific category
+// GetTransactionsByBudgetAndDateRange returns the transactions that count towards a budget between
+// two dates with their splits: those that name it and the expenses with a category or a split in
+// a category it tracks
+func (s *TransactionDatabaseService) GetTransactionsByBudgetAndDateRange(userID uuid.UUID, budgetID uuid.UUID, startDate, endDate time.Time) ([]*models.Transaction, error) {
+	var txns []*models.Transaction
+	err := s.database.
+		Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).
+		Where(`budget_id = ? OR (type = 'expense' AND (category_id IN (SELECT category_id FROM budget_categories WHERE budget_id = ?)
+			OR id IN (SELECT transaction_id FROM transaction_splits WHERE category_id IN (SELECT category_id FROM budget_categories WHERE budget_id = ?))))`, budgetID, budgetID, budgetID).
+		Preload("Splits").
+		Find(&txns).Error
+	if err != nil {
+		return nil, errors.NewDBError(err)
+	}
+	return txns, nil
+}
+
+// GetTransactionsByCategory returns all transactions for a specific category, including those with a split in it
 func (s *TransactionDatabaseService) GetTransactionsByCategory(userID uuid.UUID, categoryID uuid.UUID) ([]*models.Transaction, error) {
 	var txns []*models.Transaction
-	err := s.database.Where("user_id = ? AND category_id = ?", userID, categoryID).Find(&txns).Error
+	err := s.database.
+		Where("user_id = ?", userID).
+		Where("category_id = ? OR id IN (SELECT transaction_id FROM transaction_splits WHERE category_id = ?)", categoryID, categoryID).
+		Find(&txns).Error
 	if err != nil {
 		return nil, errors.NewDBError(err)
 	}
@@ -111,7 +189,7 @@ func (s *TransactionDatabaseService) GetTransactionsByType(userID uuid.UUID, tra
 }
 
 // GetTransactionsByAmountRange returns all transactions within an amount range
-func (s *TransactionDatabaseService) GetTransactionsByAmountRange(userID uuid.UUID, minAmount, maxAmount float64) ([]*models.Transaction, error) {
+func (s *TransactionDatabaseService) GetTransactionsByAmountRange(userID uuid.UUID, minAmount, maxAmount models.Money) ([]*models.Transaction, error) {
 	var txns []*models.Transaction
 	err := s.database.Where("user_id = ? AND amount >= ? AND amount <= ?", userID, minAmount, maxAmount).Find(&txns).Error
 	if err != nil {
@@ -121,7 +199,7 @@ func (s *TransactionDatabaseService) GetTransactionsByAmountRange(userID uuid.UU
 }
 
 // GetTransactionsWithFilters returns transactions with multiple optional filters
-func (s *TransactionDatabaseService) GetTransactionsWithFilters(userID uuid.UUID, filters map[string]interface{}) ([]*models.Transaction, error) {
+func (s *TransactionDatabaseService) GetTransactionsWithFilters(userID uuid.UUID, filters map[string]interface{}, page PageQuery) ([]*models.Transaction, error) {
 	query := s.database.Where("user_id = ?", userID)
 
 	if budgetID, ok := filters["budget_id"].(uuid.UUID); ok {
@@ -129,7 +207,16 @@ func (s *TransactionDatabaseService) GetTransactionsWithFilters(userID uuid.UUID
 	}
 
 	if categoryID, ok := filters["category_id"].(uuid.UUID); ok {
-		query = query.Where("category_id = ?", categoryID)
+		query = query.Where("category_id = ? OR id IN (SELECT transaction_id FROM transaction_splits WHERE category_id = ?)", categoryID, categoryID)
+	}
+
+	if tagIDs, ok := filters["tag_ids"].([]uuid.UUID); ok && len(tagIDs) > 0 {
+		if filters["tag_match"] == "all" {
+			query = query.Where(`id IN (SELECT transaction_id FROM transaction_tags WHERE tag_id IN ?
+				GROUP BY transaction_id HAVING COUNT(*) = ?)`, tagIDs, len(tagIDs))
+		} else {
+			query = query.Where("id IN (SELECT transaction_id FROM transaction_tags WHERE tag_id IN ?)", tagIDs)
+		}
 	}
 
 	if transactionType, ok := filters["type"].(string); ok {
@@ -144,18 +231,34 @@ func (s *TransactionDatabaseService) GetTransactionsWithFilters(userID uuid.UUID
 		query = query.Where("date <= ?", endDate)
 	}
 
-	if minAmount, ok := filters["min_amount"].(float64); ok {
+	if minAmount, ok := filters["min_amount"].(models.Money); ok {
 		query = query.Where("amount >= ?", minAmount)
 	}
 
-	if maxAmount, ok := filters["max_amount"].(float64); ok {
+	if maxAmount, ok := filters["max_amount"].(models.Money); ok {
 		query = query.Where("amount <= ?", maxAmount)
 	}
 
+	// Searching ranks every match, so the filtered rows are wrapped in a subquery that exposes the
+	// rank as a column the page can be ordered and keyed by
+	if q, ok := filters["q"].(string); ok {
+		pattern := "%" + escapeLike(q) + "%"
+		query = query.
+			Model(&models.Transaction{}).
+			Select("transactions.*, "+searchRank+" AS rank", q, q).
+			Where(searchMatch, q, pattern, pattern, q)
+		query = s.database.Table("(?) AS transactions", query)
+	}
+
 	var txns []*models.Transaction
-	err := query.Find(&txns).Error
+	err := page.apply(query).Preload("Splits").Preload("Tags").Find(&txns).Error
 	if err != nil {
 		return nil, errors.NewDBError(err)
 	}
 	return txns, nil
 }
+
+// escapeLike escapes the LIKE wildcards in user input so they match literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
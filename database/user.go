@@ -16,6 +16,7 @@ type UserDatabaseServiceInterface interface {
 	GetUserByEmail(email string) (*models.User, error)
 	UpdateUser(userID uuid.UUID, updates map[string]interface{}) error
 	DeleteUser(id uuid.UUID) error
+	DeleteUserWithData(id uuid.UUID) error
 }
 type UserDatabaseService struct {
 	database *gorm.DB
@@ -71,3 +72,33 @@ func (s *UserDatabaseService) DeleteUser(id uuid.UUID) error {
 	}
 	return nil
 }
+
+// DeleteUserWithData removes the user together with everything they own in a single transaction
+func (s *UserDatabaseService) DeleteUserWithData(id uuid.UUID) error {
+	err := s.database.Transaction(func(tx *gorm.DB) error {
+		owned := []any{
+			&models.Transaction{},
+			&models.RecurringTransaction{},
+			&models.Notification{},
+			&models.Budget{},
+			&models.Category{},
+			&models.Tag{},
+			&models.RefreshToken{},
+			&models.Session{},
+			&models.RecoveryCode{},
+			&models.PasswordResetToken{},
+			&models.EmailChangeToken{},
+			&models.EmailVerificationToken{},
+		}
+		for _, model := range owned {
+			if err := tx.Delete(model, "user_id = ?", id).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&models.User{}, "id = ?", id).Error
+	})
+	if err != nil {
+		return appErrors.NewDBError(err)
+	}
+	return nil
+}
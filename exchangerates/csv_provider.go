@@ -0,0 +1,90 @@
+package exchangerates
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+)
+
+// CSVProvider serves rates from a file, for running without network access. Every row is
+// date,base,quote,rate, e.g. 2026-01-02,EUR,USD,1.0321. A header row is skipped.
+type CSVProvider struct {
+	// days holds the dates present in the file in ascending order, rates the rows of each date
+	days  []time.Time
+	rates map[time.Time][]models.ExchangeRate
+}
+
+// NewCSVProvider reads the whole file up front so a malformed file fails at startup
+func NewCSVProvider(path string) (*CSVProvider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open exchange rate file: %w", err)
+	}
+	defer file.Close()
+
+	provider := &CSVProvider{rates: make(map[time.Time][]models.ExchangeRate)}
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	now := time.Now()
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read exchange rate file: %w", err)
+		}
+		if line == 1 && strings.EqualFold(record[0], "date") {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", record[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid date on line %d of exchange rate file: %w", line, err)
+		}
+		rate, err := strconv.ParseFloat(record[3], 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid rate on line %d of exchange rate file", line)
+		}
+
+		if _, ok := provider.rates[date]; !ok {
+			provider.days = append(provider.days, date)
+		}
+		provider.rates[date] = append(provider.rates[date], models.ExchangeRate{
+			Base:      strings.ToUpper(record[1]),
+			Quote:     strings.ToUpper(record[2]),
+			Date:      date,
+			Rate:      rate,
+			Source:    "csv",
+			CreatedAt: now,
+		})
+	}
+
+	sort.Slice(provider.days, func(i, j int) bool { return provider.days[i].Before(provider.days[j]) })
+	return provider, nil
+}
+
+func (p *CSVProvider) Name() string {
+	return "csv"
+}
+
+func (p *CSVProvider) Rates(ctx context.Context, day time.Time) ([]models.ExchangeRate, error) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+	// The latest date in the file on or before day
+	i := sort.Search(len(p.days), func(i int) bool { return p.days[i].After(day) })
+	if i == 0 {
+		return nil, nil
+	}
+	return p.rates[p.days[i-1]], nil
+}
@@ -0,0 +1,82 @@
+package exchangerates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+)
+
+// HTTPProvider fetches daily reference rates from a Frankfurter compatible API, e.g.
+// GET https://api.frankfurter.app/2026-01-02 returns {"base":"EUR","date":"2026-01-02","rates":{"USD":1.03}}
+type HTTPProvider struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPProvider(url string) *HTTPProvider {
+	return &HTTPProvider{
+		url:    strings.TrimRight(url, "/"),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type httpRatesResponse struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+func (p *HTTPProvider) Name() string {
+	return "http"
+}
+
+func (p *HTTPProvider) Rates(ctx context.Context, day time.Time) ([]models.ExchangeRate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"/"+day.Format("2006-01-02"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build exchange rate request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("exchange rate provider responded with status %d", resp.StatusCode)
+	}
+
+	var body httpRatesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode exchange rates: %w", err)
+	}
+	date, err := time.Parse("2006-01-02", body.Date)
+	if err != nil {
+		return nil, fmt.Errorf("exchange rate provider returned an invalid date %q: %w", body.Date, err)
+	}
+
+	now := time.Now()
+	rates := make([]models.ExchangeRate, 0, len(body.Rates))
+	for quote, rate := range body.Rates {
+		if rate <= 0 {
+			continue
+		}
+		rates = append(rates, models.ExchangeRate{
+			Base:      strings.ToUpper(body.Base),
+			Quote:     strings.ToUpper(quote),
+			Date:      date,
+			Rate:      rate,
+			Source:    p.Name(),
+			CreatedAt: now,
+		})
+	}
+	return rates, nil
+}
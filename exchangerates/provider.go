@@ -0,0 +1,37 @@
+package exchangerates
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/config"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+)
+
+// Provider supplies exchange rates that are not stored yet. Implementations must be safe for
+// concurrent use.
+type Provider interface {
+	Name() string
+	// Rates returns the rates in effect on day: those published that day, or the latest ones
+	// before it when none were, e.g. on weekends
+	Rates(ctx context.Context, day time.Time) ([]models.ExchangeRate, error)
+}
+
+// NewProvider returns the provider named in EXCHANGE_RATE_PROVIDER, or nil for none, in which case
+// only rates already stored are used
+func NewProvider(config *config.AppConfig) (Provider, error) {
+	switch config.ExchangeRateProvider {
+	case "", "none":
+		return nil, nil
+	case "http":
+		return NewHTTPProvider(config.ExchangeRateURL), nil
+	case "csv":
+		if config.ExchangeRateFile == "" {
+			return nil, fmt.Errorf("the csv exchange rate provider needs EXCHANGE_RATE_FILE")
+		}
+		return NewCSVProvider(config.ExchangeRateFile)
+	default:
+		return nil, fmt.Errorf("unsupported exchange rate provider %q", config.ExchangeRateProvider)
+	}
+}
@@ -0,0 +1,55 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
+)
+
+// LogMailer writes every message to the application log and appends it to a
+// file on disk. It is meant for local development where no SMTP server exists.
+type LogMailer struct {
+	from string
+	path string
+	mu   sync.Mutex
+}
+
+func NewLogMailer(from string, outputDir string) (*LogMailer, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create mail output directory: %w", err)
+	}
+	return &LogMailer{
+		from: from,
+		path: filepath.Join(outputDir, "outbox.log"),
+	}, nil
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open mail outbox: %w", err)
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "Date: %s\nFrom: %s\nTo: %s\nSubject: %s\n\n%s\n\n----\n",
+		time.Now().Format(time.RFC1123Z), m.from, msg.To, msg.Subject, msg.Body)
+	if err != nil {
+		return fmt.Errorf("failed to write mail outbox: %w", err)
+	}
+
+	utils.GetLogger().Info().
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Str("outbox", m.path).
+		Msg("Email written to outbox")
+
+	return nil
+}
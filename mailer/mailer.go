@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AlsoShantanuBorkar/budget_max/config"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers outgoing email. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer returns the mailer selected by MAIL_DRIVER
+func NewMailer(config *config.AppConfig) (Mailer, error) {
+	switch config.MailDriver {
+	case "", "log":
+		return NewLogMailer(config.MailFrom, config.MailOutputDir)
+	default:
+		return nil, fmt.Errorf("unsupported mail driver %q", config.MailDriver)
+	}
+}
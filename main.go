@@ -2,11 +2,15 @@ package main
 
 import (
 	"log"
+	"os"
 
-	"github.com/AlsoShantanuBorkar/budget_max/config"
+	appConfig "github.com/AlsoShantanuBorkar/budget_max/config"
 	"github.com/AlsoShantanuBorkar/budget_max/controllers"
 	"github.com/AlsoShantanuBorkar/budget_max/database"
+	"github.com/AlsoShantanuBorkar/budget_max/exchangerates"
+	"github.com/AlsoShantanuBorkar/budget_max/mailer"
 	"github.com/AlsoShantanuBorkar/budget_max/middleware"
+	"github.com/AlsoShantanuBorkar/budget_max/notifier"
 	"github.com/AlsoShantanuBorkar/budget_max/redis"
 	"github.com/AlsoShantanuBorkar/budget_max/routes"
 	"github.com/AlsoShantanuBorkar/budget_max/services"
@@ -18,7 +22,7 @@ func main() {
 
 	// Initialize configuration, database, Redis, and validator
 
-	config, err := config.NewConfig()
+	config, err := appConfig.NewConfig()
 
 	if err != nil {
 		log.Fatalf("Failed to initialize config: %v", err)
@@ -33,15 +37,46 @@ func main() {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
+	// "budget_max migrate ..." manages the schema and exits without starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	redisClient, err := redis.NewRedisClient(config)
 	
 	if err != nil {
 		log.Fatalf("Failed to initialize redis: %v", err)
 	}
 
+	if err := utils.InitSigningKeys(config); err != nil {
+		log.Fatalf("Failed to initialize JWT signing keys: %v", err)
+	}
+	utils.StartSigningKeyRotation()
+
+	mailService, err := mailer.NewMailer(config)
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+
+	notificationChannels, err := notifier.NewChannels(config, mailService)
+	if err != nil {
+		log.Fatalf("Failed to initialize notification channels: %v", err)
+	}
+
+	exchangeRateProvider, err := exchangerates.NewProvider(config)
+	if err != nil {
+		log.Fatalf("Failed to initialize exchange rate provider: %v", err)
+	}
+
+	rateLimiter := utils.NewRedisRateLimiter(redisClient)
+
 	r := gin.New()
 	r.Use(middleware.RequestIDMiddleware())
 	r.Use(middleware.LoggerMiddleware())
+	r.Use(middleware.GlobalRateLimitMiddleware(rateLimiter, config, appConfig.RateLimitGlobal))
 	api := r.Group("/api/v1")
 
 	// Initialize Database Services
@@ -51,31 +86,60 @@ func main() {
 	budgetDatabaseService := database.NewBudgetDatabaseService(db)
 	transactionDatabaseService := database.NewTransactionDatabaseService(db)
 	refreshTokenDatabaseService := database.NewRefreshTokenDatabaseService(db)
+	passwordResetTokenDatabaseService := database.NewPasswordResetTokenDatabaseService(db)
+	recoveryCodeDatabaseService := database.NewRecoveryCodeDatabaseService(db)
+	emailChangeTokenDatabaseService := database.NewEmailChangeTokenDatabaseService(db)
+	emailVerificationTokenDatabaseService := database.NewEmailVerificationTokenDatabaseService(db)
+	recurringTransactionDatabaseService := database.NewRecurringTransactionDatabaseService(db)
+	notificationDatabaseService := database.NewNotificationDatabaseService(db)
+	tagDatabaseService := database.NewTagDatabaseService(db)
+	exchangeRateDatabaseService := database.NewExchangeRateDatabaseService(db)
+	reportDatabaseService := database.NewReportDatabaseService(db)
 
 	// Initialize Services
-	authService := services.NewAuthService(userDatabaseService, sessionDatabaseService, refreshTokenDatabaseService, config, redisClient)
-	budgetService := services.NewBudgetService(budgetDatabaseService)
-	categoryService := services.NewCategoryService(categoryDatabaseService)
-	transactionService := services.NewTransactionService(transactionDatabaseService)
-	reportsService := services.NewReportsService(transactionDatabaseService, categoryDatabaseService, budgetDatabaseService)
+	authService := services.NewAuthService(userDatabaseService, sessionDatabaseService, refreshTokenDatabaseService, passwordResetTokenDatabaseService, recoveryCodeDatabaseService, emailVerificationTokenDatabaseService, mailService, config, redisClient)
+	userService := services.NewUserService(userDatabaseService, emailChangeTokenDatabaseService, authService, mailService, config, redisClient)
+	exchangeRateService := services.NewExchangeRateService(exchangeRateDatabaseService, exchangeRateProvider)
+	notificationService := services.NewNotificationService(notificationDatabaseService, budgetDatabaseService, transactionDatabaseService, userDatabaseService, exchangeRateService, notificationChannels)
+	budgetService := services.NewBudgetService(budgetDatabaseService, transactionDatabaseService, categoryDatabaseService, userDatabaseService, exchangeRateService, config)
+	categoryService := services.NewCategoryService(categoryDatabaseService, transactionDatabaseService, config)
+	transactionService := services.NewTransactionService(transactionDatabaseService, categoryDatabaseService, budgetDatabaseService, tagDatabaseService, userDatabaseService, notificationService)
+	recurringTransactionService := services.NewRecurringTransactionService(recurringTransactionDatabaseService, transactionDatabaseService, categoryDatabaseService, budgetDatabaseService, userDatabaseService, notificationService)
+	tagService := services.NewTagService(tagDatabaseService)
+	reportsService := services.NewReportsService(reportDatabaseService, categoryDatabaseService, budgetDatabaseService, tagDatabaseService, userDatabaseService, exchangeRateService)
 
 	// Initialize Controllers
 	authController := controllers.NewAuthController(authService)
+	userController := controllers.NewUserController(userService)
 	budgetController := controllers.NewBudgetController(budgetService)
 	categoryController := controllers.NewCategoryController(categoryService)
 	transactionController := controllers.NewTransactionController(transactionService)
+	recurringTransactionController := controllers.NewRecurringTransactionController(recurringTransactionService)
+	notificationController := controllers.NewNotificationController(notificationService)
+	tagController := controllers.NewTagController(tagService)
 	reportsController := controllers.NewReportsController(reportsService)
+	jwksController := controllers.NewJWKSController(utils.GetSigningKeys())
 
 	// Register Routes
 
 	// Unprotected Routes
-	routes.RegisterUnprotectedAuthRoutes(api, authController)
+	routes.RegisterUnprotectedAuthRoutes(api, authController, rateLimiter, config)
+	routes.RegisterUnprotectedUserRoutes(api, userController)
+	routes.RegisterJWKSRoutes(&r.RouterGroup, jwksController)
 
 	// Protected Routes
-	routes.RegisterAuthRoutes(api, authController, sessionDatabaseService)
-	routes.RegisterTransactionRoutes(api, transactionController, sessionDatabaseService)
-	routes.RegisterCategoryRoutes(api, categoryController, sessionDatabaseService)
-	routes.RegisterBudgetRoutes(api, budgetController, sessionDatabaseService)
-	routes.RegisterReportsRoutes(api, reportsController, sessionDatabaseService)
+	routes.RegisterAuthRoutes(api, authController, sessionDatabaseService, config, redisClient)
+	routes.RegisterUserRoutes(api, userController, sessionDatabaseService, config, redisClient)
+	routes.RegisterTransactionRoutes(api, transactionController, sessionDatabaseService, userDatabaseService, config, redisClient)
+	routes.RegisterCategoryRoutes(api, categoryController, sessionDatabaseService, userDatabaseService, config, redisClient)
+	routes.RegisterBudgetRoutes(api, budgetController, sessionDatabaseService, userDatabaseService, config, redisClient)
+	routes.RegisterRecurringTransactionRoutes(api, recurringTransactionController, sessionDatabaseService, userDatabaseService, config, redisClient)
+	routes.RegisterTagRoutes(api, tagController, sessionDatabaseService, userDatabaseService, config, redisClient)
+	routes.RegisterNotificationRoutes(api, notificationController, sessionDatabaseService, userDatabaseService, config, redisClient)
+	routes.RegisterReportsRoutes(api, reportsController, sessionDatabaseService, userDatabaseService, config, redisClient, rateLimiter)
+
+	services.StartRecurringTransactionScheduler(recurringTransactionService, config.RecurringSchedulerInterval)
+	services.StartBudgetRolloverScheduler(budgetService, config.BudgetRolloverInterval)
+
 	r.Run(":8080")
 }
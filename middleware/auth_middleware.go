@@ -4,13 +4,19 @@ import (
 	"strings"
 	"time"
 
+	"github.com/AlsoShantanuBorkar/budget_max/config"
 	"github.com/AlsoShantanuBorkar/budget_max/database"
 	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 )
 
-func AuthMiddleware(sessionService database.SessionDatabaseServiceInterface) gin.HandlerFunc {
+// AuthMiddleware authenticates the bearer token according to config.AuthMode. Opaque session
+// tokens are looked up in the database, JWT access tokens are verified locally and only checked
+// against the Redis revocation list.
+func AuthMiddleware(sessionService database.SessionDatabaseServiceInterface, appConfig *config.AppConfig, redisClient *redis.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenStr := c.GetHeader("Authorization")
 		if tokenStr == "" {
@@ -22,6 +28,11 @@ func AuthMiddleware(sessionService database.SessionDatabaseServiceInterface) gin
 
 		tokenStr = strings.TrimPrefix(tokenStr, "Bearer ")
 
+		if appConfig.AuthMode == config.AuthModeJWT {
+			authenticateAccessToken(c, tokenStr, appConfig, redisClient)
+			return
+		}
+
 		token, err := uuid.Parse(tokenStr)
 
 		if err != nil {
@@ -48,6 +59,36 @@ func AuthMiddleware(sessionService database.SessionDatabaseServiceInterface) gin
 		}
 
 		c.Set("user_id", session.UserID)
+		c.Set("session_id", session.ID)
 		c.Next()
 	}
 }
+
+func authenticateAccessToken(c *gin.Context, tokenStr string, appConfig *config.AppConfig, redisClient *redis.Client) {
+	claims, err := utils.VerifyAccessJWT(tokenStr, appConfig)
+	if err != nil {
+		appErr := errors.NewUnauthorizedError("Unauthorized", err)
+		c.Error(appErr)
+		c.AbortWithStatusJSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	revoked, err := utils.IsSessionAccessRevoked(claims.SessionID, redisClient, c.Request.Context())
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		c.AbortWithStatusJSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	if revoked {
+		appErr := errors.NewUnauthorizedError("Session Expired", nil)
+		c.Error(appErr)
+		c.AbortWithStatusJSON(appErr.Code, gin.H{"message": appErr.Message})
+		return
+	}
+
+	c.Set("user_id", claims.UserID)
+	c.Set("session_id", claims.SessionID)
+	c.Next()
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/config"
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitMiddleware throttles each client IP on every route it guards separately, according to
+// the named policy from config.RateLimits.
+func RateLimitMiddleware(limiter utils.RateLimiter, appConfig *config.AppConfig, policyName string) gin.HandlerFunc {
+	return rateLimit(limiter, appConfig, policyName, func(c *gin.Context) string {
+		return fmt.Sprintf("%s:%s:%s:%s", policyName, c.Request.Method, c.FullPath(), c.ClientIP())
+	})
+}
+
+// GlobalRateLimitMiddleware throttles each client IP across all routes combined
+func GlobalRateLimitMiddleware(limiter utils.RateLimiter, appConfig *config.AppConfig, policyName string) gin.HandlerFunc {
+	return rateLimit(limiter, appConfig, policyName, func(c *gin.Context) string {
+		return fmt.Sprintf("%s:%s", policyName, c.ClientIP())
+	})
+}
+
+// rateLimit counts the request under the key and rejects it once the policy is exhausted. Limiter
+// failures are logged and the request is let through so a Redis outage does not take the API
+// down with it.
+func rateLimit(limiter utils.RateLimiter, appConfig *config.AppConfig, policyName string, key func(c *gin.Context) string) gin.HandlerFunc {
+	policy, ok := appConfig.RateLimits[policyName]
+	if !ok {
+		panic(fmt.Sprintf("unknown rate limit policy %q", policyName))
+	}
+
+	return func(c *gin.Context) {
+		result, err := limiter.Allow(c.Request.Context(), key(c), policy.Limit, policy.Window)
+		if err != nil {
+			utils.GetLogger().Error().
+				Err(err).
+				Str("request_id", c.GetString("request_id")).
+				Str("policy", policyName).
+				Msg("Rate limiter unavailable, allowing request")
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(time.Until(result.ResetAt).Seconds()))
+			c.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+
+			appErr := errors.NewTooManyRequestsError("Too many requests", nil)
+			c.Error(appErr)
+			c.AbortWithStatusJSON(appErr.Code, gin.H{"message": appErr.Message})
+			return
+		}
+
+		c.Next()
+	}
+}
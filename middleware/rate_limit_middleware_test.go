@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/config"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// newRateLimitedRouter serves /a and /b behind the middleware built by guard, every response 200
+func newRateLimitedRouter(guard gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(guard)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/a", ok)
+	r.GET("/b", ok)
+	return r
+}
+
+func rateLimitConfig(policy config.RateLimitPolicy) *config.AppConfig {
+	return &config.AppConfig{RateLimits: map[string]config.RateLimitPolicy{"test": policy}}
+}
+
+func get(r *gin.Engine, path string, clientIP string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = clientIP + ":1234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddlewareRejectsOnceThePolicyIsExhausted(t *testing.T) {
+	appConfig := rateLimitConfig(config.RateLimitPolicy{Limit: 2, Window: time.Minute})
+	r := newRateLimitedRouter(RateLimitMiddleware(utils.NewMemoryRateLimiter(), appConfig, "test"))
+
+	for i, wantRemaining := range []string{"1", "0"} {
+		w := get(r, "/a", "10.0.0.1")
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: got status %d, want 200", i+1, w.Code)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d: X-RateLimit-Limit = %q, want 2", i+1, got)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != wantRemaining {
+			t.Errorf("request %d: X-RateLimit-Remaining = %q, want %s", i+1, got, wantRemaining)
+		}
+		if got := w.Header().Get("Retry-After"); got != "" {
+			t.Errorf("request %d: unexpected Retry-After %q", i+1, got)
+		}
+	}
+
+	w := get(r, "/a", "10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request: got status %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("X-RateLimit-Remaining"); got != "0" {
+		t.Errorf("X-RateLimit-Remaining = %q, want 0", got)
+	}
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 1 || retryAfter > 60 {
+		t.Errorf("Retry-After = %q, want 1 to 60 seconds", w.Header().Get("Retry-After"))
+	}
+	reset, err := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil || reset < time.Now().Unix() || reset > time.Now().Add(time.Minute).Unix()+1 {
+		t.Errorf("X-RateLimit-Reset = %q, want a time within the next minute", w.Header().Get("X-RateLimit-Reset"))
+	}
+}
+
+func TestRateLimitMiddlewareCountsEachRouteAndClientSeparately(t *testing.T) {
+	appConfig := rateLimitConfig(config.RateLimitPolicy{Limit: 1, Window: time.Minute})
+	r := newRateLimitedRouter(RateLimitMiddleware(utils.NewMemoryRateLimiter(), appConfig, "test"))
+
+	if w := get(r, "/a", "10.0.0.1"); w.Code != http.StatusOK {
+		t.Fatalf("first request: got status %d, want 200", w.Code)
+	}
+	if w := get(r, "/b", "10.0.0.1"); w.Code != http.StatusOK {
+		t.Errorf("other route: got status %d, want 200", w.Code)
+	}
+	if w := get(r, "/a", "10.0.0.2"); w.Code != http.StatusOK {
+		t.Errorf("other client: got status %d, want 200", w.Code)
+	}
+	if w := get(r, "/a", "10.0.0.1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("repeated request: got status %d, want 429", w.Code)
+	}
+}
+
+func TestGlobalRateLimitMiddlewareCountsAllRoutesTogether(t *testing.T) {
+	appConfig := rateLimitConfig(config.RateLimitPolicy{Limit: 1, Window: time.Minute})
+	r := newRateLimitedRouter(GlobalRateLimitMiddleware(utils.NewMemoryRateLimiter(), appConfig, "test"))
+
+	if w := get(r, "/a", "10.0.0.1"); w.Code != http.StatusOK {
+		t.Fatalf("first request: got status %d, want 200", w.Code)
+	}
+	if w := get(r, "/b", "10.0.0.1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("other route: got status %d, want 429", w.Code)
+	}
+	if w := get(r, "/b", "10.0.0.2"); w.Code != http.StatusOK {
+		t.Errorf("other client: got status %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimitMiddlewareWindowSlides(t *testing.T) {
+	window := 100 * time.Millisecond
+	appConfig := rateLimitConfig(config.RateLimitPolicy{Limit: 2, Window: window})
+	r := newRateLimitedRouter(RateLimitMiddleware(utils.NewMemoryRateLimiter(), appConfig, "test"))
+
+	get(r, "/a", "10.0.0.1")
+	time.Sleep(window / 2)
+	get(r, "/a", "10.0.0.1")
+	if w := get(r, "/a", "10.0.0.1"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("over the limit: got status %d, want 429", w.Code)
+	}
+
+	// Only the first request has left the window, so exactly one more fits
+	time.Sleep(window/2 + 10*time.Millisecond)
+	if w := get(r, "/a", "10.0.0.1"); w.Code != http.StatusOK {
+		t.Errorf("after the oldest request expired: got status %d, want 200", w.Code)
+	}
+	if w := get(r, "/a", "10.0.0.1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second request after expiry: got status %d, want 429", w.Code)
+	}
+}
+
+func TestRateLimitMiddlewarePanicsOnUnknownPolicy(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an unknown policy")
+		}
+	}()
+	RateLimitMiddleware(utils.NewMemoryRateLimiter(), rateLimitConfig(config.RateLimitPolicy{Limit: 1, Window: time.Minute}), "missing")
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// ReauthMiddleware guards sensitive operations. It must run after AuthMiddleware and only lets
+// the request through if the current session re-authenticated via POST /auth/reauth recently.
+func ReauthMiddleware(redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionIdRaw, exists := c.Get("session_id")
+		sessionId, ok := sessionIdRaw.(uuid.UUID)
+		if !exists || !ok {
+			appErr := errors.NewUnauthorizedError("Unauthorized", nil)
+			c.Error(appErr)
+			c.AbortWithStatusJSON(appErr.Code, gin.H{"message": appErr.Message})
+			return
+		}
+
+		reauthenticated, err := utils.IsSessionReauthenticated(sessionId, redisClient, c.Request.Context())
+		if err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			c.AbortWithStatusJSON(appErr.Code, gin.H{"message": appErr.Message})
+			return
+		}
+
+		if !reauthenticated {
+			appErr := errors.NewForbiddenError("Re-authentication required", nil)
+			c.Error(appErr)
+			c.AbortWithStatusJSON(appErr.Code, gin.H{
+				"message":         appErr.Message,
+				"reauth_required": true,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"github.com/AlsoShantanuBorkar/budget_max/config"
+	"github.com/AlsoShantanuBorkar/budget_max/database"
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// VerifiedEmailMiddleware keeps users with an unverified email address out of the routes it guards
+// when EMAIL_VERIFICATION_POLICY is "restrict". It must run after AuthMiddleware. Under any other
+// policy it is a no-op, so the extra user lookup only happens when the restriction is enabled.
+func VerifiedEmailMiddleware(userDatabaseService database.UserDatabaseServiceInterface, appConfig *config.AppConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if appConfig.EmailVerificationPolicy != config.EmailVerificationRestrict {
+			c.Next()
+			return
+		}
+
+		userId, ok := utils.ParseUserID(c)
+		if !ok {
+			return
+		}
+
+		user, err := userDatabaseService.GetUserByID(userId)
+		if err != nil || user == nil {
+			appErr := errors.NewUnauthorizedError("Unauthorized", err)
+			c.Error(appErr)
+			c.AbortWithStatusJSON(appErr.Code, gin.H{"message": appErr.Message})
+			return
+		}
+
+		if !user.EmailVerified {
+			appErr := errors.NewForbiddenError("Email verification required", nil)
+			c.Error(appErr)
+			c.AbortWithStatusJSON(appErr.Code, gin.H{
+				"message":                     appErr.Message,
+				"email_verification_required": true,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
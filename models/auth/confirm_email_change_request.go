@@ -0,0 +1,5 @@
+package auth
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" validate:"required"`
+}
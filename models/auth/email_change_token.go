@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// models/email_change_token.go
+type EmailChangeToken struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;" validate:"required,uuid4"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index" validate:"required,uuid4"`
+	NewEmail  string    `json:"new_email" gorm:"not null" validate:"required,email"`
+	Token     string    `json:"-" gorm:"type:text;uniqueIndex;not null" validate:"required"` // SHA-256 hash of the emailed token
+	ExpiresAt time.Time `json:"expires_at" gorm:"type:timestamptz;not null" validate:"required"`
+	Used      bool      `json:"used" gorm:"default:false"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamptz;not null" validate:"required"`
+}
@@ -0,0 +1,17 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// models/email_verification_token.go
+type EmailVerificationToken struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;" validate:"required,uuid4"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index" validate:"required,uuid4"`
+	Token     string    `json:"-" gorm:"type:text;uniqueIndex;not null" validate:"required"` // SHA-256 hash of the emailed token
+	ExpiresAt time.Time `json:"expires_at" gorm:"type:timestamptz;not null" validate:"required"`
+	Used      bool      `json:"used" gorm:"default:false"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamptz;not null" validate:"required"`
+}
@@ -0,0 +1,6 @@
+package auth
+
+type ReauthRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"omitempty"` // TOTP code, required when 2FA is enabled
+}
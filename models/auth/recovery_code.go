@@ -0,0 +1,16 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// models/recovery_code.go
+type RecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;" validate:"required,uuid4"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index" validate:"required,uuid4"`
+	CodeHash  string     `json:"-" gorm:"type:text;not null;index" validate:"required"`
+	UsedAt    *time.Time `json:"used_at,omitempty" gorm:"type:timestamptz"`
+	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamptz;not null" validate:"required"`
+}
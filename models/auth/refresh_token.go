@@ -8,11 +8,13 @@ import (
 
 // models/refresh_token.go
 type RefreshToken struct {
-	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;" validate:"required,uuid4"`
-	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index" validate:"required,uuid4"`
-	SessionID uuid.UUID `json:"session_id" gorm:"type:uuid;not null;index" validate:"required,uuid4"`
-	Token     uuid.UUID `json:"token" gorm:"type:uuid;uniqueIndex;not null" validate:"required"`
-	ExpiresAt time.Time `json:"expires_at" gorm:"type:timestamptz;not null" validate:"required"`
-	Revoked   bool      `json:"revoked" gorm:"default:false"`
-	CreatedAt time.Time `json:"created_at" gorm:"type:timestamptz;not null" validate:"required"`
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;" validate:"required,uuid4"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index" validate:"required,uuid4"`
+	SessionID uuid.UUID  `json:"session_id" gorm:"type:uuid;not null;index" validate:"required,uuid4"`
+	Token     uuid.UUID  `json:"token" gorm:"type:uuid;uniqueIndex;not null" validate:"required"`
+	FamilyID  uuid.UUID  `json:"family_id" gorm:"type:uuid;not null;index" validate:"required,uuid4"` // shared by every token rotated from the same login
+	ParentID  *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid"`                                // token this one replaced
+	ExpiresAt time.Time  `json:"expires_at" gorm:"type:timestamptz;not null" validate:"required"`
+	Revoked   bool       `json:"revoked" gorm:"default:false"`
+	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamptz;not null" validate:"required"`
 }
@@ -1,7 +1,8 @@
 package auth
 
 type TwoFactorLoginRequest struct {
-	Email string `json:"email" validate:"required,email"`
-	Code  string `json:"code" validate:"required"`
-	Token string `json:"token" validate:"required"`
+	Email        string `json:"email" validate:"required,email"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
+	Token        string `json:"token" validate:"required"`
 }
@@ -0,0 +1,8 @@
+package auth
+
+type UpdateProfileRequest struct {
+	Name         *string `json:"name" validate:"omitempty,max=100"`
+	BaseCurrency *string `json:"base_currency" validate:"omitempty,iso4217"`
+	Timezone     *string `json:"timezone" validate:"omitempty,timezone"`
+	WeekStart    *int    `json:"week_start" validate:"omitempty,min=0,max=6"`
+}
@@ -7,10 +7,28 @@ import (
 )
 
 type User struct {
-	ID               uuid.UUID `gorm:"type:uuid;primaryKey;" json:"id" validate:"required,uuid4"`
-	Email            string    `gorm:"uniqueIndex;not null" json:"email" validate:"required,email"`
-	Password         string    `gorm:"not null" json:"-" validate:"required,min=8"`
-	TwoFactorEnabled bool      `gorm:"default:false" json:"two_factor_enabled"`
-	TwoFactorSecret  string    `gorm:"" json:"-"`
-	CreatedAt        time.Time `gorm:"autoCreateTime" json:"created_at" validate:"required,datetime"`
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey;" json:"id" validate:"required,uuid4"`
+	Email            string     `gorm:"uniqueIndex;not null" json:"email" validate:"required,email"`
+	Name             string     `gorm:"type:varchar(100)" json:"name"`
+	EmailVerified    bool       `gorm:"default:false" json:"email_verified"`
+	EmailVerifiedAt  *time.Time `gorm:"type:timestamptz" json:"email_verified_at,omitempty"`
+	Password         string     `gorm:"not null" json:"-" validate:"required,min=8"`
+	TwoFactorEnabled bool       `gorm:"default:false" json:"two_factor_enabled"`
+	TwoFactorSecret  string     `gorm:"" json:"-"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"created_at" validate:"required,datetime"`
+	// BaseCurrency is the ISO 4217 code new transactions and budgets default to and reports are shown in
+	BaseCurrency string `gorm:"type:varchar(3);not null;default:USD" json:"base_currency"`
+	// Timezone is the IANA name of the timezone report dates and budget periods are in
+	Timezone string `gorm:"type:varchar(64);not null;default:UTC" json:"timezone"`
+	// WeekStart is the first day of the user's weeks, 0 for Sunday through 6 for Saturday
+	WeekStart time.Weekday `gorm:"type:smallint;not null;default:1" json:"week_start"`
+}
+
+// Location returns the user's timezone, falling back to UTC should the stored name be unknown
+func (u *User) Location() *time.Location {
+	location, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
 }
@@ -3,6 +3,7 @@ package budget
 import (
 	"time"
 
+	"github.com/AlsoShantanuBorkar/budget_max/models/currency"
 	"github.com/google/uuid"
 )
 
@@ -15,14 +16,55 @@ const (
 	Yearly  BudgetType = "year"
 )
 
+// What a recurring budget carries into its next period when one ends
+const (
+	// CarryOverNone starts every period from the budget amount
+	CarryOverNone = "none"
+	// CarryOverUnspent adds what was left of the period to the next one
+	CarryOverUnspent = "unspent"
+	// CarryOverOverspent takes what the period went over out of the next one
+	CarryOverOverspent = "overspent"
+	// CarryOverAll carries both
+	CarryOverAll = "all"
+)
+
 // models/budget.go
 type Budget struct {
-	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;" validate:"required,uuid4"`
-	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index" validate:"required,uuid4"`
-	Type      BudgetType `json:"type" gorm:"type:varchar(10);not null" validate:"required,oneof=week month year"`
-	Name      string     `json:"name" gorm:"type:varchar(255);not null" validate:"required"`
-	StartDate time.Time  `json:"start_date" gorm:"type:timestamp;not null" validate:"required"`
-	EndDate   time.Time  `json:"end_date" gorm:"type:timestamp;not null" validate:"required,gtfield=StartDate"`
-	Amount    float64    `json:"amount" gorm:"type:decimal(12,2);not null" validate:"required,gt=0"`
-	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;" validate:"required,uuid4"`
+	UserID    uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index" validate:"required,uuid4"`
+	Type      BudgetType     `json:"type" gorm:"type:varchar(10);not null" validate:"required,oneof=week month year"`
+	Name      string         `json:"name" gorm:"type:varchar(255);not null" validate:"required"`
+	StartDate time.Time      `json:"start_date" gorm:"type:timestamp;not null" validate:"required"`
+	EndDate   time.Time      `json:"end_date" gorm:"type:timestamp;not null" validate:"required,gtfield=StartDate"`
+	Amount    currency.Money `json:"amount" gorm:"type:decimal(12,2);not null" validate:"required,gt=0"`
+	Currency  string         `json:"currency" gorm:"type:varchar(3);not null"` // transactions in other currencies are converted into it
+	CreatedAt time.Time      `json:"created_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	// Recurring budgets move StartDate and EndDate to the next period when the current one ends
+	Recurring bool   `json:"recurring" gorm:"not null"`
+	CarryOver string `json:"carry_over" gorm:"type:varchar(10);not null;default:none"`
+	// CarriedOver is what the previous period left (or took) from this one, on top of Amount
+	CarriedOver currency.Money `json:"carried_over" gorm:"type:decimal(12,2);not null;default:0"`
+	// Periods are computed from the anchor dates so month ends do not drift
+	PeriodIndex     int       `json:"-" gorm:"not null;default:0"`
+	AnchorStartDate time.Time `json:"-" gorm:"type:timestamp;not null"`
+	AnchorEndDate   time.Time `json:"-" gorm:"type:timestamp;not null"`
+	// AlertThresholds are the percentages of the period's amount at which the user is notified
+	AlertThresholds []int `json:"alert_thresholds" gorm:"type:jsonb;not null;serializer:json"`
+	// CategoryIDs are the categories whose expenses count towards the budget without naming it
+	CategoryIDs []uuid.UUID `json:"category_ids" gorm:"-"`
+}
+
+// DefaultAlertThresholds are used when a budget is created without its own
+var DefaultAlertThresholds = []int{80, 100}
+
+// Frequency maps the budget type to the recurrence its periods follow
+func (t BudgetType) Frequency() string {
+	switch t {
+	case Weekly:
+		return "weekly"
+	case Yearly:
+		return "yearly"
+	default:
+		return "monthly"
+	}
 }
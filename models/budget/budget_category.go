@@ -0,0 +1,9 @@
+package budget
+
+import "github.com/google/uuid"
+
+// BudgetCategory links a budget to a category it tracks
+type BudgetCategory struct {
+	BudgetID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CategoryID uuid.UUID `gorm:"type:uuid;primaryKey"`
+}
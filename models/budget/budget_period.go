@@ -0,0 +1,19 @@
+package budget
+
+import (
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/models/currency"
+	"github.com/google/uuid"
+)
+
+// BudgetPeriod records one period of a budget as it was when the period started
+type BudgetPeriod struct {
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	BudgetID    uuid.UUID      `json:"budget_id" gorm:"type:uuid;not null"`
+	StartDate   time.Time      `json:"start_date" gorm:"type:timestamp;not null"`
+	EndDate     time.Time      `json:"end_date" gorm:"type:timestamp;not null"`
+	Amount      currency.Money `json:"amount" gorm:"type:decimal(12,2);not null"`
+	CarriedOver currency.Money `json:"carried_over" gorm:"type:decimal(12,2);not null;default:0"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"type:timestamp;not null"`
+}
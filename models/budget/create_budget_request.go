@@ -1,11 +1,24 @@
 package budget
 
-import "time"
+import (
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/models/currency"
+)
 
 type CreateBudgetRequest struct {
-	Type      BudgetType `json:"type" validate:"required,oneof=week month year"`
-	Name      string     `json:"name" validate:"required"`
-	StartDate time.Time  `json:"start_date" validate:"required"`
-	EndDate   time.Time  `json:"end_date" validate:"required,gtfield=StartDate"`
-	Amount    float64    `json:"amount" validate:"required,gt=0"`
+	Type      BudgetType     `json:"type" validate:"required,oneof=week month year"`
+	Name      string         `json:"name" validate:"required"`
+	StartDate time.Time      `json:"start_date" validate:"required"`
+	EndDate   time.Time      `json:"end_date" validate:"required,gtfield=StartDate"`
+	Amount    currency.Money `json:"amount" validate:"required,gt=0"`
+	// Currency defaults to the user's base currency and cannot be changed later
+	Currency string `json:"currency" validate:"omitempty,iso4217"`
+	// Recurring defaults to true
+	Recurring *bool  `json:"recurring"`
+	CarryOver string `json:"carry_over" validate:"omitempty,oneof=none unspent overspent all"`
+	// AlertThresholds are percentages of the budget amount, e.g. [50, 80, 100]. An empty list turns alerts off.
+	AlertThresholds []int `json:"alert_thresholds" validate:"omitempty,max=10,dive,min=1,max=1000"`
+	// CategoryIDs are expense categories tracked by the budget, e.g. Groceries and Dining
+	CategoryIDs []string `json:"category_ids" validate:"omitempty,max=20,dive,uuid4"`
 }
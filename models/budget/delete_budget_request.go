@@ -0,0 +1,7 @@
+package budget
+
+// DeleteBudgetRequest is read from the query string, e.g. ?on_delete=reassign&reassign_to=<id>
+type DeleteBudgetRequest struct {
+	OnDelete   string `form:"on_delete" validate:"omitempty,oneof=nullify reassign block"`
+	ReassignTo string `form:"reassign_to" validate:"omitempty,uuid4"`
+}
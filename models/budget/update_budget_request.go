@@ -1,10 +1,20 @@
 package budget
 
-import "time"
+import (
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/models/currency"
+)
 
 type UpdateBudgetRequest struct {
-	Name      *string    `json:"name,omitempty" validate:"omitempty"`
-	StartDate *time.Time `json:"start_date,omitempty" validate:"omitempty"`
-	EndDate   *time.Time `json:"end_date,omitempty" validate:"omitempty,gtfield=StartDate"`
-	Amount    *float64   `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	Name      *string         `json:"name,omitempty" validate:"omitempty"`
+	StartDate *time.Time      `json:"start_date,omitempty" validate:"omitempty"`
+	EndDate   *time.Time      `json:"end_date,omitempty" validate:"omitempty,gtfield=StartDate"`
+	Amount    *currency.Money `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	Recurring *bool           `json:"recurring,omitempty"`
+	CarryOver *string         `json:"carry_over,omitempty" validate:"omitempty,oneof=none unspent overspent all"`
+	// AlertThresholds are percentages of the budget amount, e.g. [50, 80, 100]. Leaving it out keeps the current ones and an empty list turns alerts off.
+	AlertThresholds []int `json:"alert_thresholds" validate:"omitempty,max=10,dive,min=1,max=1000"`
+	// CategoryIDs are expense categories tracked by the budget, e.g. Groceries and Dining. Leaving it out keeps the current ones and an empty list tracks none.
+	CategoryIDs []string `json:"category_ids" validate:"omitempty,max=20,dive,uuid4"`
 }
@@ -0,0 +1,16 @@
+package currency
+
+import "time"
+
+// DefaultCurrency is the base currency of users who have not picked one
+const DefaultCurrency = "USD"
+
+// ExchangeRate is how much one unit of Base was worth in Quote on Date
+type ExchangeRate struct {
+	Base      string    `json:"base" gorm:"type:varchar(3);primaryKey"`
+	Quote     string    `json:"quote" gorm:"type:varchar(3);primaryKey"`
+	Date      time.Time `json:"date" gorm:"type:date;primaryKey"`
+	Rate      float64   `json:"rate" gorm:"not null"`
+	Source    string    `json:"source" gorm:"type:varchar(50);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamptz;not null"`
+}
@@ -0,0 +1,151 @@
+package currency
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount with two decimal places, held as a whole number of cents so that sums
+// never pick up floating point error. It reads and writes JSON as a number with two decimals, e.g.
+// 1203.50, and maps to DECIMAL(12,2) columns.
+type Money int64
+
+// Cents returns the amount as a whole number of cents
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+// Mul multiplies the amount by rate, rounding to the nearest cent with halves away from zero
+func (m Money) Mul(rate float64) Money {
+	product := new(big.Rat).SetFloat64(rate)
+	if product == nil {
+		return 0
+	}
+	product.Mul(product, new(big.Rat).SetInt64(int64(m)))
+	cents, _ := roundCents(product)
+	return cents
+}
+
+// Div splits the amount into n equal parts, rounding to the nearest cent with halves away from
+// zero. n must be positive.
+func (m Money) Div(n int64) (Money, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("cannot split an amount into %d parts", n)
+	}
+	cents, _ := roundCents(big.NewRat(int64(m), n))
+	return cents, nil
+}
+
+// String formats the amount with exactly two decimals, e.g. -12.50
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+	}
+	units, rest := cents/100, cents%100
+	if rest < 0 {
+		units, rest = -units, -rest
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, units, rest)
+}
+
+// ParseMoney reads a decimal amount such as 12.5 or -1203.49. Amounts with more than two decimals
+// are rejected rather than rounded so that what was entered is what gets stored.
+func ParseMoney(s string) (Money, error) {
+	amount, ok := new(big.Rat).SetString(s)
+	if !ok || strings.Contains(s, "/") {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	cents := amount.Mul(amount, big.NewRat(100, 1))
+	if !cents.IsInt() {
+		return 0, fmt.Errorf("amount %q has more than two decimal places", s)
+	}
+	if !cents.Num().IsInt64() {
+		return 0, fmt.Errorf("amount %q is out of range", s)
+	}
+	return Money(cents.Num().Int64()), nil
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts the amount as a number or as a string holding one
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(string(data))
+		if err != nil {
+			return fmt.Errorf("invalid amount %s", data)
+		}
+		data = []byte(unquoted)
+	}
+	amount, err := ParseMoney(string(data))
+	if err != nil {
+		return err
+	}
+	*m = amount
+	return nil
+}
+
+// Value stores the amount as its decimal text so the database keeps it exact
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan reads a NUMERIC column, rounding to the nearest cent when it holds more decimals, e.g. an
+// average computed in SQL
+func (m *Money) Scan(src any) error {
+	var amount *big.Rat
+	switch value := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case int64:
+		*m = Money(value * 100)
+		return nil
+	case float64:
+		amount = new(big.Rat).SetFloat64(value)
+	case string:
+		amount, _ = new(big.Rat).SetString(value)
+	case []byte:
+		amount, _ = new(big.Rat).SetString(string(value))
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	if amount == nil {
+		return fmt.Errorf("cannot scan %v into Money", src)
+	}
+
+	cents, ok := roundCents(amount.Mul(amount, big.NewRat(100, 1)))
+	if !ok {
+		return fmt.Errorf("amount %v is out of range", src)
+	}
+	*m = cents
+	return nil
+}
+
+// roundCents rounds a number of cents to a whole one, halves away from zero
+func roundCents(cents *big.Rat) (Money, bool) {
+	num := new(big.Int).Abs(cents.Num())
+	den := cents.Denom()
+
+	// floor((2|num| + den) / 2den) is |num/den| rounded half up
+	rounded := new(big.Int).Lsh(num, 1)
+	rounded.Add(rounded, den)
+	rounded.Quo(rounded, new(big.Int).Lsh(den, 1))
+	if cents.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+	if !rounded.IsInt64() {
+		return 0, false
+	}
+	return Money(rounded.Int64()), true
+}
@@ -4,25 +4,50 @@ import (
 	"github.com/AlsoShantanuBorkar/budget_max/models/auth"
 	"github.com/AlsoShantanuBorkar/budget_max/models/budget"
 	"github.com/AlsoShantanuBorkar/budget_max/models/categories"
+	"github.com/AlsoShantanuBorkar/budget_max/models/currency"
+	"github.com/AlsoShantanuBorkar/budget_max/models/notifications"
+	"github.com/AlsoShantanuBorkar/budget_max/models/pagination"
+	"github.com/AlsoShantanuBorkar/budget_max/models/recurring"
+	"github.com/AlsoShantanuBorkar/budget_max/models/tags"
 	"github.com/AlsoShantanuBorkar/budget_max/models/transactions"
 )
 
 type (
 	// Auth models
-	User                   = auth.User
-	Session                = auth.Session
-	RefreshToken           = auth.RefreshToken
-	PasswordResetToken     = auth.PasswordResetToken
-	AuthRequest            = auth.AuthRequest
-	RefreshTokensRequest   = auth.RefreshTokensRequest
-	TwoFactorVerifyRequest = auth.TwoFactorVerifyRequest
-	TwoFactorLoginRequest  = auth.TwoFactorLoginRequest
-	TwoFAClaims            = auth.TwoFAClaims
+	User                      = auth.User
+	Session                   = auth.Session
+	RefreshToken              = auth.RefreshToken
+	PasswordResetToken        = auth.PasswordResetToken
+	RecoveryCode              = auth.RecoveryCode
+	AuthRequest               = auth.AuthRequest
+	RefreshTokensRequest      = auth.RefreshTokensRequest
+	TwoFactorVerifyRequest    = auth.TwoFactorVerifyRequest
+	TwoFactorLoginRequest     = auth.TwoFactorLoginRequest
+	TwoFAClaims               = auth.TwoFAClaims
+	AccessTokenClaims         = auth.AccessTokenClaims
+	ForgotPasswordRequest     = auth.ForgotPasswordRequest
+	ResetPasswordRequest      = auth.ResetPasswordRequest
+	ReauthRequest             = auth.ReauthRequest
+	EmailChangeToken          = auth.EmailChangeToken
+	UpdateProfileRequest      = auth.UpdateProfileRequest
+	ChangePasswordRequest     = auth.ChangePasswordRequest
+	ChangeEmailRequest        = auth.ChangeEmailRequest
+	ConfirmEmailChangeRequest = auth.ConfirmEmailChangeRequest
+	EmailVerificationToken    = auth.EmailVerificationToken
+	VerifyEmailRequest        = auth.VerifyEmailRequest
+	ResendVerificationRequest = auth.ResendVerificationRequest
 
 	// Category models
 	Category              = categories.Category
 	CreateCategoryRequest = categories.CreateCategoryRequest
 	UpdateCategoryRequest = categories.UpdateCategoryRequest
+	DeleteCategoryRequest = categories.DeleteCategoryRequest
+
+	// Tag models
+	Tag              = tags.Tag
+	TransactionTag   = tags.TransactionTag
+	CreateTagRequest = tags.CreateTagRequest
+	UpdateTagRequest = tags.UpdateTagRequest
 
 	// Transaction models
 	Transaction               = transactions.Transaction
@@ -31,9 +56,36 @@ type (
 	TransactionFiltersRequest = transactions.TransactionFiltersRequest
 	DateRangeRequest          = transactions.DateRangeRequest
 	AmountRangeRequest        = transactions.AmountRangeRequest
+	TransactionSplit          = transactions.TransactionSplit
+	SplitRequest              = transactions.SplitRequest
 
 	// Budget models
 	Budget              = budget.Budget
 	CreateBudgetRequest = budget.CreateBudgetRequest
 	UpdateBudgetRequest = budget.UpdateBudgetRequest
+	DeleteBudgetRequest = budget.DeleteBudgetRequest
+	BudgetPeriod        = budget.BudgetPeriod
+	BudgetCategory      = budget.BudgetCategory
+
+	// Notification models
+	Notification         = notifications.Notification
+	NotificationsRequest = notifications.NotificationsRequest
+
+	// Recurring transaction models
+	RecurringTransaction              = recurring.RecurringTransaction
+	RecurringTransactionException     = recurring.RecurringTransactionException
+	CreateRecurringTransactionRequest = recurring.CreateRecurringTransactionRequest
+	UpdateRecurringTransactionRequest = recurring.UpdateRecurringTransactionRequest
+	OccurrenceExceptionRequest        = recurring.OccurrenceExceptionRequest
+	Occurrence                        = recurring.Occurrence
+	PreviewRequest                    = recurring.PreviewRequest
+
+	// Currency models
+	ExchangeRate = currency.ExchangeRate
+	Money        = currency.Money
+
+	// Pagination models
+	PageRequest = pagination.PageRequest
 )
+
+type Page[T any] = pagination.Page[T]
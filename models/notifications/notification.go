@@ -0,0 +1,29 @@
+package notifications
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification types
+const (
+	// TypeBudgetThreshold is sent when spending in a budget period reaches one of its alert thresholds
+	TypeBudgetThreshold = "budget_threshold"
+)
+
+type Notification struct {
+	ID      uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID  uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
+	Type    string    `json:"type" gorm:"type:varchar(50);not null"`
+	Title   string    `json:"title" gorm:"type:varchar(255);not null"`
+	Message string    `json:"message" gorm:"type:text;not null"`
+	// BudgetID, Threshold and PeriodStart are set for budget threshold notifications
+	BudgetID    *uuid.UUID `json:"budget_id,omitempty" gorm:"type:uuid"`
+	Threshold   *int       `json:"threshold,omitempty"`
+	PeriodStart *time.Time `json:"period_start,omitempty" gorm:"type:timestamp"`
+	// DedupKey identifies the event, so the same threshold in the same period is only reported once
+	DedupKey  string     `json:"-" gorm:"type:varchar(255);not null"`
+	ReadAt    *time.Time `json:"read_at" gorm:"type:timestamptz"`
+	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamptz;not null"`
+}
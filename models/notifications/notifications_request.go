@@ -0,0 +1,6 @@
+package notifications
+
+// NotificationsRequest is read from the query string next to the pagination parameters
+type NotificationsRequest struct {
+	Unread bool `form:"unread"`
+}
@@ -0,0 +1,15 @@
+package pagination
+
+// PageRequest is read from the query string of list endpoints, e.g. ?limit=50&sort=-date&cursor=<next_cursor>.
+// A leading "-" on sort orders descending.
+type PageRequest struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=100"`
+	Sort   string `form:"sort"`
+}
+
+// Page is one page of a list. NextCursor is nil on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor *string
+}
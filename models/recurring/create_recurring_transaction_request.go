@@ -0,0 +1,19 @@
+package recurring
+
+import "github.com/AlsoShantanuBorkar/budget_max/models/currency"
+
+type CreateRecurringTransactionRequest struct {
+	Name       string         `json:"name" validate:"required,min=1"`
+	Amount     currency.Money `json:"amount" validate:"required,gt=0"`
+	Currency   string         `json:"currency" validate:"omitempty,iso4217"` // defaults to the user's base currency
+	Type       string         `json:"type" validate:"required,oneof=expense income"`
+	Note       string         `json:"note"`
+	CategoryID string         `json:"category_id" validate:"omitempty,uuid4"`
+	BudgetID   string         `json:"budget_id" validate:"omitempty,uuid4"`
+	Frequency  string         `json:"frequency" validate:"required,oneof=daily weekly monthly yearly"`
+	// Interval defaults to 1, e.g. 2 with weekly is every other week
+	Interval  int     `json:"interval" validate:"omitempty,min=1,max=365"`
+	StartDate string  `json:"start_date" validate:"required,datetime"`
+	EndDate   *string `json:"end_date" validate:"omitempty,datetime"`
+	Count     *int    `json:"count" validate:"omitempty,min=1"`
+}
@@ -0,0 +1,23 @@
+package recurring
+
+import (
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/models/currency"
+)
+
+// Occurrence is one upcoming occurrence of a recurring transaction with its exception applied
+type Occurrence struct {
+	OccurrenceDate time.Time      `json:"occurrence_date"`
+	Date           time.Time      `json:"date"`
+	Name           string         `json:"name"`
+	Amount         currency.Money `json:"amount"`
+	Note           string         `json:"note"`
+	Skipped        bool           `json:"skipped"`
+	Modified       bool           `json:"modified"`
+}
+
+// PreviewRequest is read from the query string, e.g. ?count=12
+type PreviewRequest struct {
+	Count int `form:"count" validate:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,12 @@
+package recurring
+
+import "github.com/AlsoShantanuBorkar/budget_max/models/currency"
+
+// OccurrenceExceptionRequest skips one occurrence or overrides what is recorded for it
+type OccurrenceExceptionRequest struct {
+	Skip   bool            `json:"skip"`
+	Name   *string         `json:"name" validate:"omitempty,min=1"`
+	Amount *currency.Money `json:"amount" validate:"omitempty,gt=0"`
+	Note   *string         `json:"note"`
+	Date   *string         `json:"date" validate:"omitempty,datetime"`
+}
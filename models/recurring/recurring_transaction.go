@@ -0,0 +1,37 @@
+package recurring
+
+import (
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/models/currency"
+	"github.com/google/uuid"
+)
+
+// RecurringTransaction is a template the scheduler turns into a Transaction on every occurrence
+// of its schedule
+type RecurringTransaction struct {
+	ID         uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name       string         `json:"name" gorm:"type:varchar(255);not null"`
+	Amount     currency.Money `json:"amount" gorm:"type:decimal(12,2);not null"`
+	Currency   string         `json:"currency" gorm:"type:varchar(3);not null"`
+	Type       string         `json:"type" gorm:"type:varchar(10);not null"`
+	Note       string         `json:"note" gorm:"type:text"`
+	CategoryID *uuid.UUID     `json:"category_id,omitempty" gorm:"type:uuid"`
+	BudgetID   *uuid.UUID     `json:"budget_id,omitempty" gorm:"type:uuid"`
+
+	Frequency string     `json:"frequency" gorm:"type:varchar(10);not null"`
+	Interval  int        `json:"interval" gorm:"column:repeat_interval;not null;default:1"`
+	StartDate time.Time  `json:"start_date" gorm:"type:timestamptz;not null"`
+	EndDate   *time.Time `json:"end_date,omitempty" gorm:"type:timestamptz"`
+	Count     *int       `json:"count,omitempty"`
+
+	// OccurrenceIndex is the index of the next occurrence to record, everything before it has been
+	// recorded, skipped or deliberately passed over after a schedule change
+	OccurrenceIndex int `json:"-" gorm:"not null;default:0"`
+	// NextOccurrenceAt is nil once the schedule has ended
+	NextOccurrenceAt *time.Time `json:"next_occurrence_at" gorm:"type:timestamptz;index"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamptz;not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"type:timestamptz;not null"`
+}
@@ -0,0 +1,25 @@
+package recurring
+
+import (
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/models/currency"
+	"github.com/google/uuid"
+)
+
+// RecurringTransactionException skips or changes a single occurrence before it is recorded
+type RecurringTransactionException struct {
+	ID                     uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	RecurringTransactionID uuid.UUID `json:"recurring_transaction_id" gorm:"type:uuid;not null;uniqueIndex:idx_recurring_exception_occurrence"`
+	// OccurrenceDate is the scheduled time of the occurrence this exception applies to
+	OccurrenceDate time.Time `json:"occurrence_date" gorm:"type:timestamptz;not null;uniqueIndex:idx_recurring_exception_occurrence"`
+	Skip           bool      `json:"skip" gorm:"not null;default:false"`
+
+	// Overrides applied to the recorded transaction, nil keeps the template's value
+	Name   *string         `json:"name,omitempty" gorm:"type:varchar(255)"`
+	Amount *currency.Money `json:"amount,omitempty" gorm:"type:decimal(12,2)"`
+	Note   *string         `json:"note,omitempty" gorm:"type:text"`
+	Date   *time.Time      `json:"date,omitempty" gorm:"type:timestamptz"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamptz;not null"`
+}
@@ -0,0 +1,19 @@
+package recurring
+
+import "github.com/AlsoShantanuBorkar/budget_max/models/currency"
+
+type UpdateRecurringTransactionRequest struct {
+	Name       *string         `json:"name" validate:"omitempty,min=1"`
+	Amount     *currency.Money `json:"amount" validate:"omitempty,gt=0"`
+	Currency   *string         `json:"currency" validate:"omitempty,iso4217"`
+	Type       *string         `json:"type" validate:"omitempty,oneof=expense income"`
+	Note       *string         `json:"note"`
+	CategoryID *string         `json:"category_id" validate:"omitempty,uuid4"`
+	BudgetID   *string         `json:"budget_id" validate:"omitempty,uuid4"`
+	// Changing any schedule field restarts the series from its first occurrence after now
+	Frequency *string `json:"frequency" validate:"omitempty,oneof=daily weekly monthly yearly"`
+	Interval  *int    `json:"interval" validate:"omitempty,min=1,max=365"`
+	StartDate *string `json:"start_date" validate:"omitempty,datetime"`
+	EndDate   *string `json:"end_date" validate:"omitempty,datetime"`
+	Count     *int    `json:"count" validate:"omitempty,min=1"`
+}
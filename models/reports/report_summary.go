@@ -1,64 +1,104 @@
 package reports
 
-import "github.com/google/uuid"
+import (
+	"time"
 
+	"github.com/AlsoShantanuBorkar/budget_max/models/currency"
+	"github.com/google/uuid"
+)
+
+// BudgetSummary totals every transaction of the budget, with the results of each period in Periods, newest first.
+// All amounts are in Currency.
 type BudgetSummary struct {
-	BudgetID      uuid.UUID `json:"budget_id"`
-	BudgetName    string    `json:"budget_name"`
-	BudgetAmount  float64   `json:"budget_amount"`
-	TotalExpenses float64   `json:"total_expenses"`
-	TotalIncome   float64   `json:"total_income"`
-	NetBalance    float64   `json:"net_balance"`
+	BudgetID      uuid.UUID             `json:"budget_id"`
+	BudgetName    string                `json:"budget_name"`
+	BudgetAmount  currency.Money        `json:"budget_amount"`
+	Currency      string                `json:"currency"`
+	TotalExpenses currency.Money        `json:"total_expenses"`
+	TotalIncome   currency.Money        `json:"total_income"`
+	NetBalance    currency.Money        `json:"net_balance"`
+	Periods       []BudgetPeriodSummary `json:"periods"`
+}
+
+// BudgetPeriodSummary is how one period of a budget went. Available is the period's amount plus
+// what the previous period carried over, and Remaining is what is left of it after expenses.
+type BudgetPeriodSummary struct {
+	StartDate     time.Time      `json:"start_date"`
+	EndDate       time.Time      `json:"end_date"`
+	Amount        currency.Money `json:"amount"`
+	CarriedOver   currency.Money `json:"carried_over"`
+	Available     currency.Money `json:"available"`
+	TotalExpenses currency.Money `json:"total_expenses"`
+	TotalIncome   currency.Money `json:"total_income"`
+	Remaining     currency.Money `json:"remaining"`
 }
 
 type WeeklySummary struct {
-	StartDate     string  `json:"start_date"`
-	EndDate       string  `json:"end_date"`
-	TotalExpenses float64 `json:"total_expenses"`
-	TotalIncome   float64 `json:"total_income"`
-	NetBalance    float64 `json:"net_balance"`
+	StartDate     string         `json:"start_date"`
+	EndDate       string         `json:"end_date"`
+	Currency      string         `json:"currency"`
+	TotalExpenses currency.Money `json:"total_expenses"`
+	TotalIncome   currency.Money `json:"total_income"`
+	NetBalance    currency.Money `json:"net_balance"`
 }
 
 type MonthlySummary struct {
-	Month         string  `json:"month"`
-	TotalExpenses float64 `json:"total_expenses"`
-	TotalIncome   float64 `json:"total_income"`
-	NetBalance    float64 `json:"net_balance"`
+	Month         string         `json:"month"`
+	Currency      string         `json:"currency"`
+	TotalExpenses currency.Money `json:"total_expenses"`
+	TotalIncome   currency.Money `json:"total_income"`
+	NetBalance    currency.Money `json:"net_balance"`
 }
 
 type YearlySummary struct {
-	Year          string  `json:"year"`
-	TotalExpenses float64 `json:"total_expenses"`
-	TotalIncome   float64 `json:"total_income"`
-	NetBalance    float64 `json:"net_balance"`
+	Year          string         `json:"year"`
+	Currency      string         `json:"currency"`
+	TotalExpenses currency.Money `json:"total_expenses"`
+	TotalIncome   currency.Money `json:"total_income"`
+	NetBalance    currency.Money `json:"net_balance"`
 }
 
 type CategorySummary struct {
-	CategoryID    uuid.UUID `json:"category_id"`
-	CategoryName  string    `json:"category_name"`
-	TotalExpenses float64   `json:"total_expenses"`
-	TotalIncome   float64   `json:"total_income"`
-	NetBalance    float64   `json:"net_balance"`
+	CategoryID    uuid.UUID      `json:"category_id"`
+	CategoryName  string         `json:"category_name"`
+	Currency      string         `json:"currency"`
+	TotalExpenses currency.Money `json:"total_expenses"`
+	TotalIncome   currency.Money `json:"total_income"`
+	NetBalance    currency.Money `json:"net_balance"`
+}
+
+// TagSummary totals the transactions with a tag. A transaction with several tags counts in full
+// towards each of them.
+type TagSummary struct {
+	TagID         uuid.UUID      `json:"tag_id"`
+	TagName       string         `json:"tag_name"`
+	Currency      string         `json:"currency"`
+	TotalExpenses currency.Money `json:"total_expenses"`
+	TotalIncome   currency.Money `json:"total_income"`
+	NetBalance    currency.Money `json:"net_balance"`
 }
 
 type CustomDateRangeSummary struct {
-	StartDate     string  `json:"start_date"`
-	EndDate       string  `json:"end_date"`
-	TotalExpenses float64 `json:"total_expenses"`
-	TotalIncome   float64 `json:"total_income"`
-	NetBalance    float64 `json:"net_balance"`
+	StartDate     string         `json:"start_date"`
+	EndDate       string         `json:"end_date"`
+	Currency      string         `json:"currency"`
+	TotalExpenses currency.Money `json:"total_expenses"`
+	TotalIncome   currency.Money `json:"total_income"`
+	NetBalance    currency.Money `json:"net_balance"`
 }
 type DailyAverageSummary struct {
-	Date          string  `json:"date"`
-	TotalExpenses float64 `json:"total_expenses"`
-	TotalIncome   float64 `json:"total_income"`
-	NetBalance    float64 `json:"net_balance"`
+	Date          string         `json:"date"`
+	Currency      string         `json:"currency"`
+	TotalExpenses currency.Money `json:"total_expenses"`
+	TotalIncome   currency.Money `json:"total_income"`
+	NetBalance    currency.Money `json:"net_balance"`
 }
 
 type TopCategory struct {
-	CategoryID   string  `json:"category_id"`
-	CategoryName string  `json:"category_name"`
-	Amount       float64 `json:"amount"`
-	Type         string  `json:"type"` // income or expense
-	Rank         int     `json:"rank"`
+	CategoryID   string         `json:"category_id"`
+	CategoryName string         `json:"category_name"`
+	Currency     string         `json:"currency"`
+	Amount       currency.Money `json:"amount"`
+	Type         string         `json:"type"` // income or expense
+	Rank         int            `json:"rank"`
 }
@@ -0,0 +1,22 @@
+package reports
+
+import (
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/models/currency"
+	"github.com/google/uuid"
+)
+
+// ReportTotal is the sum of a user's transactions of one type and currency, within one group
+// (category, tag, budget or budget period) when the report is grouped. Totals in a currency other
+// than the report's are also split by Day so each can be converted at that day's rate.
+type ReportTotal struct {
+	GroupID   *uuid.UUID
+	GroupName *string
+	Type      string
+	Currency  string
+	Day       *time.Time
+	Amount    currency.Money
+	// Bucket is the local start of the time series bucket, only set for time series
+	Bucket *time.Time
+}
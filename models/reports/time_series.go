@@ -0,0 +1,44 @@
+package reports
+
+import (
+	"github.com/AlsoShantanuBorkar/budget_max/models/currency"
+	"github.com/google/uuid"
+)
+
+// TimeSeriesRequest is read from the query string, e.g. ?interval=month&start=2026-01-01&end=2026-12-31
+type TimeSeriesRequest struct {
+	Interval string `form:"interval" validate:"required,oneof=day week month quarter year"`
+	Start    string `form:"start" validate:"required,datetime=2006-01-02"`
+	End      string `form:"end" validate:"required,datetime=2006-01-02"`
+	// GroupBy defaults to type, which only splits each bucket into income and expenses
+	GroupBy  string `form:"group_by" validate:"omitempty,oneof=category budget type"`
+	Currency string `form:"currency" validate:"omitempty,iso4217"`
+	// Timezone is the IANA name of the timezone buckets start and end in, the user's own by default
+	Timezone string `form:"timezone" validate:"omitempty,timezone"`
+}
+
+// TimeSeries splits a date range into consecutive buckets, oldest first. Every bucket is present
+// even when it has no transactions, and every group appearing in the range is present in each of them.
+type TimeSeries struct {
+	Interval string             `json:"interval"`
+	GroupBy  string             `json:"group_by"`
+	Timezone string             `json:"timezone"`
+	Currency string             `json:"currency"`
+	Buckets  []TimeSeriesBucket `json:"buckets"`
+}
+
+// TimeSeriesBucket totals one bucket. StartDate and EndDate are clipped to the requested range.
+type TimeSeriesBucket struct {
+	CustomDateRangeSummary
+	Groups []TimeSeriesGroup `json:"groups,omitempty"`
+}
+
+// TimeSeriesGroup totals one category or budget within a bucket. ID is null for the transactions
+// without a category or budget.
+type TimeSeriesGroup struct {
+	ID            *uuid.UUID     `json:"id"`
+	Name          string         `json:"name"`
+	TotalExpenses currency.Money `json:"total_expenses"`
+	TotalIncome   currency.Money `json:"total_income"`
+	NetBalance    currency.Money `json:"net_balance"`
+}
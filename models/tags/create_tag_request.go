@@ -0,0 +1,6 @@
+package tags
+
+type CreateTagRequest struct {
+	Name  string  `json:"name" validate:"required,min=1,max=50"`
+	Color *string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+}
@@ -0,0 +1,16 @@
+package tags
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag is a free-form label a user can put on any number of transactions, independent of their category
+type Tag struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name      string    `json:"name" gorm:"type:varchar(50);not null"`
+	Color     *string   `json:"color" gorm:"type:varchar(7);default:null"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamptz;not null"`
+}
@@ -0,0 +1,9 @@
+package tags
+
+import "github.com/google/uuid"
+
+// TransactionTag links a transaction to one of its tags
+type TransactionTag struct {
+	TransactionID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TagID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+}
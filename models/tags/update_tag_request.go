@@ -0,0 +1,6 @@
+package tags
+
+type UpdateTagRequest struct {
+	Name  *string `json:"name,omitempty" validate:"omitempty,min=1,max=50"`
+	Color *string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+}
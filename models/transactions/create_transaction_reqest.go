@@ -1,11 +1,17 @@
 package transactions
 
+import "github.com/AlsoShantanuBorkar/budget_max/models/currency"
+
 type CreateTransactionRequest struct {
-	Amount      float64 `json:"amount" validate:"required,gt=0"`
-	Type        string  `json:"type" validate:"required,oneof=expense income"`
-	Name        string  `json:"name" validate:"required,min=1"`
-	Date        string  `json:"date" validate:"required,datetime"`
-	Note        string  `json:"note" validate:"omitempty"`
-	CategoryIDs string  `json:"category_ids" validate:"omitempty,uuid4"` // optional
-	BudgetID    string  `json:"budget_id" validate:"omitempty,uuid4"`
+	Amount      currency.Money `json:"amount" validate:"required,gt=0"`
+	Currency    string         `json:"currency" validate:"omitempty,iso4217"` // defaults to the user's base currency
+	Type        string         `json:"type" validate:"required,oneof=expense income"`
+	Name        string         `json:"name" validate:"required,min=1"`
+	Date        string         `json:"date" validate:"required,datetime"`
+	Note        string         `json:"note" validate:"omitempty"`
+	CategoryIDs string         `json:"category_ids" validate:"omitempty,uuid4"` // optional
+	BudgetID    string         `json:"budget_id" validate:"omitempty,uuid4"`
+	// Splits must add up to Amount
+	Splits []SplitRequest `json:"splits" validate:"omitempty,min=2,max=50,dive"`
+	TagIDs []string       `json:"tag_ids" validate:"omitempty,max=20,dive,uuid4"`
 }
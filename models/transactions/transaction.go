@@ -3,20 +3,32 @@ package transactions
 import (
 	"time"
 
+	"github.com/AlsoShantanuBorkar/budget_max/models/currency"
+	"github.com/AlsoShantanuBorkar/budget_max/models/tags"
 	"github.com/google/uuid"
 )
 
 // models/transaction.go
 type Transaction struct {
-	ID     uuid.UUID `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
-	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index" validate:"required,uuid4"`
-	Amount float64   `json:"amount" gorm:"not null" validate:"required,gt=0"`
-	Type   string    `json:"type" gorm:"type:varchar(10);not null" validate:"required,oneof=expense income"`
+	ID     uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey" validate:"required,uuid4"`
+	UserID uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index" validate:"required,uuid4"`
+	Amount currency.Money `json:"amount" gorm:"type:decimal(12,2);not null" validate:"required,gt=0"`
+	Type   string         `json:"type" gorm:"type:varchar(10);not null" validate:"required,oneof=expense income"`
 
 	Name       string     `json:"name" gorm:"type:varchar(255);not null" validate:"required"`
 	Date       time.Time  `json:"date" gorm:"type:timestamptz;not null" validate:"required"`
 	Note       string     `json:"note" gorm:"type:text"`
+	Currency   string     `json:"currency" gorm:"type:varchar(3);not null"` // ISO 4217 code of Amount
 	BudgetID   *uuid.UUID `json:"budget_id,omitempty" gorm:"type:uuid" validate:"omitempty,uuid4"`
 	CategoryID *uuid.UUID `json:"category_id,omitempty" gorm:"type:uuid" validate:"omitempty,uuid4"`
 	CreatedAt  time.Time  `json:"created_at" gorm:"type:timestamptz;not null" validate:"required"`
+	// RecurringTransactionID is set on transactions recorded by the recurring transaction scheduler
+	RecurringTransactionID *uuid.UUID `json:"recurring_transaction_id,omitempty" gorm:"type:uuid"`
+	// Splits divide the amount across categories. A split transaction counts towards its splits'
+	// categories in reports instead of CategoryID.
+	Splits []TransactionSplit `json:"splits,omitempty" gorm:"foreignKey:TransactionID"`
+	Tags   []tags.Tag         `json:"tags,omitempty" gorm:"many2many:transaction_tags"`
+
+	// Rank is the search relevance, only set when listing with a text query
+	Rank *float64 `json:"rank,omitempty" gorm:"->;-:migration"`
 }
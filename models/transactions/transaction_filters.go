@@ -1,13 +1,20 @@
 package transactions
 
+import "github.com/AlsoShantanuBorkar/budget_max/models/currency"
+
 type TransactionFiltersRequest struct {
-	BudgetID   *string  `json:"budget_id" validate:"omitempty,uuid4"`
-	CategoryID *string  `json:"category_id" validate:"omitempty,uuid4"`
-	Type       *string  `json:"type" validate:"omitempty,oneof=expense income"`
-	StartDate  *string  `json:"start_date" validate:"omitempty,datetime"`
-	EndDate    *string  `json:"end_date" validate:"omitempty,datetime"`
-	MinAmount  *float64 `json:"min_amount" validate:"omitempty,gte=0"`
-	MaxAmount  *float64 `json:"max_amount" validate:"omitempty,gte=0"`
+	BudgetID   *string         `json:"budget_id" validate:"omitempty,uuid4"`
+	CategoryID *string         `json:"category_id" validate:"omitempty,uuid4"`
+	Type       *string         `json:"type" validate:"omitempty,oneof=expense income"`
+	StartDate  *string         `json:"start_date" validate:"omitempty,datetime"`
+	EndDate    *string         `json:"end_date" validate:"omitempty,datetime"`
+	MinAmount  *currency.Money `json:"min_amount" validate:"omitempty,gte=0"`
+	MaxAmount  *currency.Money `json:"max_amount" validate:"omitempty,gte=0"`
+	// Q searches names and notes, matching whole words, substrings and near misses
+	Q *string `json:"q" validate:"omitempty,max=200"`
+	// TagIDs keeps transactions with any of the tags, or with all of them when TagMatch is "all"
+	TagIDs   []string `json:"tag_ids" validate:"omitempty,max=20,dive,uuid4"`
+	TagMatch *string  `json:"tag_match" validate:"omitempty,oneof=any all"`
 }
 
 type DateRangeRequest struct {
@@ -16,6 +23,6 @@ type DateRangeRequest struct {
 }
 
 type AmountRangeRequest struct {
-	MinAmount float64 `json:"min_amount" validate:"required,gte=0"`
-	MaxAmount float64 `json:"max_amount" validate:"required,gte=0"`
+	MinAmount currency.Money `json:"min_amount" validate:"required,gte=0"`
+	MaxAmount currency.Money `json:"max_amount" validate:"required,gte=0"`
 }
@@ -0,0 +1,25 @@
+package transactions
+
+import (
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/models/currency"
+	"github.com/google/uuid"
+)
+
+// TransactionSplit is one line of a transaction divided across categories. The splits of a
+// transaction add up to its amount.
+type TransactionSplit struct {
+	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	TransactionID uuid.UUID      `json:"transaction_id" gorm:"type:uuid;not null"`
+	CategoryID    *uuid.UUID     `json:"category_id,omitempty" gorm:"type:uuid"`
+	Amount        currency.Money `json:"amount" gorm:"type:decimal(12,2);not null"`
+	Note          string         `json:"note" gorm:"type:text"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"type:timestamptz;not null"`
+}
+
+type SplitRequest struct {
+	CategoryID string         `json:"category_id" validate:"omitempty,uuid4"`
+	Amount     currency.Money `json:"amount" validate:"required,gt=0"`
+	Note       string         `json:"note"`
+}
@@ -1,11 +1,18 @@
 package transactions
 
+import "github.com/AlsoShantanuBorkar/budget_max/models/currency"
+
 type UpdateTransactionRequest struct {
-	Amount     *float64 `json:"amount" validate:"omitempty,gt=0"`
-	Type       *string  `json:"type" validate:"omitempty,oneof=expense income"`
-	Date       *string  `json:"date" validate:"omitempty,datetime"`
-	Note       *string  `json:"note"`
-	CategoryID *string  `json:"category_id" validate:"omitempty,uuid4"`
-	BudgetID   *string  `json:"budget_id" validate:"omitempty,uuid4"`
-	Name       *string  `json:"name" validate:"omitempty,min=1"`
+	Amount     *currency.Money `json:"amount" validate:"omitempty,gt=0"`
+	Currency   *string         `json:"currency" validate:"omitempty,iso4217"`
+	Type       *string         `json:"type" validate:"omitempty,oneof=expense income"`
+	Date       *string         `json:"date" validate:"omitempty,datetime"`
+	Note       *string         `json:"note"`
+	CategoryID *string         `json:"category_id" validate:"omitempty,uuid4"`
+	BudgetID   *string         `json:"budget_id" validate:"omitempty,uuid4"`
+	Name       *string         `json:"name" validate:"omitempty,min=1"`
+	// Splits replace the current ones and must add up to the amount. An empty list removes them.
+	Splits []SplitRequest `json:"splits" validate:"omitempty,min=2,max=50,dive"`
+	// TagIDs replace the current tags. An empty list removes them.
+	TagIDs []string `json:"tag_ids" validate:"omitempty,max=20,dive,uuid4"`
 }
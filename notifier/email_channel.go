@@ -0,0 +1,34 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AlsoShantanuBorkar/budget_max/mailer"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+)
+
+// EmailChannel sends notifications through the configured mailer
+type EmailChannel struct {
+	mailer mailer.Mailer
+	appURL string
+}
+
+func NewEmailChannel(mailService mailer.Mailer, appURL string) *EmailChannel {
+	return &EmailChannel{mailer: mailService, appURL: appURL}
+}
+
+func (ch *EmailChannel) Name() string {
+	return "email"
+}
+
+func (ch *EmailChannel) Deliver(ctx context.Context, recipient Recipient, notification *models.Notification) error {
+	if recipient.Email == "" {
+		return fmt.Errorf("recipient has no email address")
+	}
+	return ch.mailer.Send(ctx, mailer.Message{
+		To:      recipient.Email,
+		Subject: notification.Title,
+		Body:    fmt.Sprintf("%s\n\nSee all your notifications at %s\n", notification.Message, ch.appURL),
+	})
+}
@@ -0,0 +1,56 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AlsoShantanuBorkar/budget_max/config"
+	"github.com/AlsoShantanuBorkar/budget_max/mailer"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+)
+
+// Recipient is who a notification is delivered to
+type Recipient struct {
+	Email string
+	Name  string
+}
+
+// Channel delivers a stored notification somewhere the user will see it. Implementations must be
+// safe for concurrent use.
+type Channel interface {
+	Name() string
+	Deliver(ctx context.Context, recipient Recipient, notification *models.Notification) error
+}
+
+// NewChannels returns the channels named in NOTIFICATION_CHANNELS
+func NewChannels(config *config.AppConfig, mailService mailer.Mailer) ([]Channel, error) {
+	channels := make([]Channel, 0, len(config.NotificationChannels))
+	for _, name := range config.NotificationChannels {
+		switch name {
+		case "in_app":
+			channels = append(channels, InAppChannel{})
+		case "email":
+			channels = append(channels, NewEmailChannel(mailService, config.AppURL))
+		case "webhook":
+			if config.NotificationWebhookURL == "" {
+				return nil, fmt.Errorf("the webhook notification channel needs NOTIFICATION_WEBHOOK_URL")
+			}
+			channels = append(channels, NewWebhookChannel(config.NotificationWebhookURL, config.NotificationWebhookSecret))
+		default:
+			return nil, fmt.Errorf("unsupported notification channel %q", name)
+		}
+	}
+	return channels, nil
+}
+
+// InAppChannel leaves the notification where GET /notifications finds it. Notifications are always
+// stored, so delivering in app needs nothing more.
+type InAppChannel struct{}
+
+func (InAppChannel) Name() string {
+	return "in_app"
+}
+
+func (InAppChannel) Deliver(ctx context.Context, recipient Recipient, notification *models.Notification) error {
+	return nil
+}
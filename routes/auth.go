@@ -1,27 +1,46 @@
 package routes
 
 import (
+	"github.com/AlsoShantanuBorkar/budget_max/config"
 	"github.com/AlsoShantanuBorkar/budget_max/controllers"
 	"github.com/AlsoShantanuBorkar/budget_max/database"
 	"github.com/AlsoShantanuBorkar/budget_max/middleware"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
-func RegisterUnprotectedAuthRoutes(rg *gin.RouterGroup, ctrl controllers.AuthControllerInterface) {
+func RegisterUnprotectedAuthRoutes(rg *gin.RouterGroup, ctrl controllers.AuthControllerInterface, rateLimiter utils.RateLimiter, appConfig *config.AppConfig) {
 	auth := rg.Group("/auth")
 
-	auth.POST("/signup", ctrl.Signup)
+	// Credential endpoints are throttled per IP regardless of the email being tried
+	auth.Use(middleware.RateLimitMiddleware(rateLimiter, appConfig, config.RateLimitAuth))
+	auth.POST("/signup", middleware.RateLimitMiddleware(rateLimiter, appConfig, config.RateLimitSignup), ctrl.Signup)
 	auth.POST("/login", ctrl.Login)
 	auth.POST("/refresh", ctrl.RefreshToken)
 	auth.POST("/2fa/login", ctrl.LoginWith2FA)
+	auth.POST("/password/forgot", ctrl.ForgotPassword)
+	auth.POST("/password/reset", ctrl.ResetPassword)
+	auth.POST("/verify-email", ctrl.VerifyEmail)
+	auth.POST("/verify-email/resend", ctrl.ResendVerificationEmail)
 }
 
-func RegisterAuthRoutes(rg *gin.RouterGroup, ctrl controllers.AuthControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface) {
+func RegisterAuthRoutes(rg *gin.RouterGroup, ctrl controllers.AuthControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface, appConfig *config.AppConfig, redisClient *redis.Client) {
 	auth := rg.Group("/auth")
 
-	auth.Use(middleware.AuthMiddleware(sessionDatabaseService))
+	auth.Use(middleware.AuthMiddleware(sessionDatabaseService, appConfig, redisClient))
 	auth.POST("/logout", ctrl.Logout)
 	auth.POST("/2fa/setup", ctrl.Generate2FA)
 	auth.POST("/2fa/verify", ctrl.Verify2FA)
-	auth.PUT("/2fa/disable", ctrl.Disable2FA)
+	auth.GET("/2fa/recovery-codes", ctrl.GetRecoveryCodesCount)
+	auth.POST("/2fa/recovery-codes", ctrl.RegenerateRecoveryCodes)
+	auth.GET("/sessions", ctrl.GetSessions)
+	auth.DELETE("/sessions/:id", ctrl.RevokeSession)
+	auth.POST("/sessions/revoke-others", ctrl.RevokeOtherSessions)
+	auth.POST("/reauth", ctrl.Reauthenticate)
+
+	// Sensitive operations need a recent POST /auth/reauth on the current session
+	sensitive := auth.Group("")
+	sensitive.Use(middleware.ReauthMiddleware(redisClient))
+	sensitive.PUT("/2fa/disable", ctrl.Disable2FA)
 }
@@ -1,16 +1,19 @@
 package routes
 
 import (
+	"github.com/AlsoShantanuBorkar/budget_max/config"
 	"github.com/AlsoShantanuBorkar/budget_max/controllers"
 	"github.com/AlsoShantanuBorkar/budget_max/database"
 	"github.com/AlsoShantanuBorkar/budget_max/middleware"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
-func RegisterBudgetRoutes(rg *gin.RouterGroup, ctrl controllers.BudgetControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface) {
+func RegisterBudgetRoutes(rg *gin.RouterGroup, ctrl controllers.BudgetControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface, userDatabaseService database.UserDatabaseServiceInterface, appConfig *config.AppConfig, redisClient *redis.Client) {
 	budget := rg.Group("/budget")
 
-	budget.Use(middleware.AuthMiddleware(sessionDatabaseService))
+	budget.Use(middleware.AuthMiddleware(sessionDatabaseService, appConfig, redisClient))
+	budget.Use(middleware.VerifiedEmailMiddleware(userDatabaseService, appConfig))
 
 	budget.GET("/", ctrl.GetBudgetsByUserID)
 	budget.GET("/:id", ctrl.GetBudgetByID)
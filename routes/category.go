@@ -1,17 +1,20 @@
 package routes
 
 import (
+	"github.com/AlsoShantanuBorkar/budget_max/config"
 	"github.com/AlsoShantanuBorkar/budget_max/controllers"
 	"github.com/AlsoShantanuBorkar/budget_max/database"
 	"github.com/AlsoShantanuBorkar/budget_max/middleware"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
-func RegisterCategoryRoutes(rg *gin.RouterGroup, ctrl controllers.CategoryControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface) {
+func RegisterCategoryRoutes(rg *gin.RouterGroup, ctrl controllers.CategoryControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface, userDatabaseService database.UserDatabaseServiceInterface, appConfig *config.AppConfig, redisClient *redis.Client) {
 	category := rg.Group("/category")
 
 	// All category routes require authentication
-	category.Use(middleware.AuthMiddleware(sessionDatabaseService))
+	category.Use(middleware.AuthMiddleware(sessionDatabaseService, appConfig, redisClient))
+	category.Use(middleware.VerifiedEmailMiddleware(userDatabaseService, appConfig))
 
 	// GET /api/v1/category - Get all categories for the user
 	category.GET("/", ctrl.GetAllCategories)
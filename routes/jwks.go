@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/AlsoShantanuBorkar/budget_max/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterJWKSRoutes(rg *gin.RouterGroup, ctrl controllers.JWKSControllerInterface) {
+	wellKnown := rg.Group("/.well-known")
+
+	wellKnown.GET("/jwks.json", ctrl.GetJWKS)
+}
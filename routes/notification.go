@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/AlsoShantanuBorkar/budget_max/config"
+	"github.com/AlsoShantanuBorkar/budget_max/controllers"
+	"github.com/AlsoShantanuBorkar/budget_max/database"
+	"github.com/AlsoShantanuBorkar/budget_max/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+func RegisterNotificationRoutes(rg *gin.RouterGroup, ctrl controllers.NotificationControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface, userDatabaseService database.UserDatabaseServiceInterface, appConfig *config.AppConfig, redisClient *redis.Client) {
+	notifications := rg.Group("/notifications")
+
+	notifications.Use(middleware.AuthMiddleware(sessionDatabaseService, appConfig, redisClient))
+	notifications.Use(middleware.VerifiedEmailMiddleware(userDatabaseService, appConfig))
+
+	notifications.GET("/", ctrl.GetNotifications)
+	notifications.POST("/read", ctrl.MarkAllNotificationsRead)
+	notifications.POST("/:id/read", ctrl.MarkNotificationRead)
+}
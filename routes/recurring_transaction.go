@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/AlsoShantanuBorkar/budget_max/config"
+	"github.com/AlsoShantanuBorkar/budget_max/controllers"
+	"github.com/AlsoShantanuBorkar/budget_max/database"
+	"github.com/AlsoShantanuBorkar/budget_max/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+func RegisterRecurringTransactionRoutes(rg *gin.RouterGroup, ctrl controllers.RecurringTransactionControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface, userDatabaseService database.UserDatabaseServiceInterface, appConfig *config.AppConfig, redisClient *redis.Client) {
+	recurring := rg.Group("/recurring-transaction")
+
+	recurring.Use(middleware.AuthMiddleware(sessionDatabaseService, appConfig, redisClient))
+	recurring.Use(middleware.VerifiedEmailMiddleware(userDatabaseService, appConfig))
+
+	recurring.GET("/", ctrl.GetRecurringTransactionsByUserID)
+	recurring.GET("/:id", ctrl.GetRecurringTransactionByID)
+	recurring.POST("/", ctrl.CreateRecurringTransaction)
+	recurring.PUT("/:id", ctrl.UpdateRecurringTransaction)
+	recurring.DELETE("/:id", ctrl.DeleteRecurringTransaction)
+	recurring.GET("/:id/preview", ctrl.PreviewOccurrences)
+	recurring.PUT("/:id/occurrences/:date", ctrl.SetOccurrenceException)
+	recurring.DELETE("/:id/occurrences/:date", ctrl.DeleteOccurrenceException)
+}
@@ -1,15 +1,20 @@
 package routes
 
 import (
+	"github.com/AlsoShantanuBorkar/budget_max/config"
 	"github.com/AlsoShantanuBorkar/budget_max/controllers"
 	"github.com/AlsoShantanuBorkar/budget_max/database"
 	"github.com/AlsoShantanuBorkar/budget_max/middleware"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
-func RegisterReportsRoutes(rg *gin.RouterGroup, ctrl controllers.ReportsControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface) {
+func RegisterReportsRoutes(rg *gin.RouterGroup, ctrl controllers.ReportsControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface, userDatabaseService database.UserDatabaseServiceInterface, appConfig *config.AppConfig, redisClient *redis.Client, rateLimiter utils.RateLimiter) {
 	reportsGroup := rg.Group("/reports")
-	reportsGroup.Use(middleware.AuthMiddleware(sessionDatabaseService))
+	reportsGroup.Use(middleware.RateLimitMiddleware(rateLimiter, appConfig, config.RateLimitReports))
+	reportsGroup.Use(middleware.AuthMiddleware(sessionDatabaseService, appConfig, redisClient))
+	reportsGroup.Use(middleware.VerifiedEmailMiddleware(userDatabaseService, appConfig))
 
 	// Budget reports
 	reportsGroup.GET("/budget/:budget_id", ctrl.GetBudgetSummary)
@@ -25,4 +30,10 @@ func RegisterReportsRoutes(rg *gin.RouterGroup, ctrl controllers.ReportsControll
 	reportsGroup.GET("/category/:category_id", ctrl.GetCategorySummary)
 	reportsGroup.GET("/categories", ctrl.GetAllCategoriesSummary)
 	reportsGroup.GET("/top-categories", ctrl.GetTopCategories)
+
+	// Tag reports
+	reportsGroup.GET("/tags", ctrl.GetAllTagsSummary)
+
+	// Trend reports
+	reportsGroup.GET("/timeseries", ctrl.GetTimeSeries)
 }
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/AlsoShantanuBorkar/budget_max/config"
+	"github.com/AlsoShantanuBorkar/budget_max/controllers"
+	"github.com/AlsoShantanuBorkar/budget_max/database"
+	"github.com/AlsoShantanuBorkar/budget_max/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+func RegisterTagRoutes(rg *gin.RouterGroup, ctrl controllers.TagControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface, userDatabaseService database.UserDatabaseServiceInterface, appConfig *config.AppConfig, redisClient *redis.Client) {
+	tag := rg.Group("/tag")
+
+	tag.Use(middleware.AuthMiddleware(sessionDatabaseService, appConfig, redisClient))
+	tag.Use(middleware.VerifiedEmailMiddleware(userDatabaseService, appConfig))
+
+	tag.GET("/", ctrl.GetAllTags)
+	tag.GET("/:id", ctrl.GetTagByID)
+	tag.POST("/", ctrl.CreateTag)
+	tag.PUT("/:id", ctrl.UpdateTag)
+	tag.DELETE("/:id", ctrl.DeleteTag)
+}
@@ -1,16 +1,19 @@
 package routes
 
 import (
+	"github.com/AlsoShantanuBorkar/budget_max/config"
 	"github.com/AlsoShantanuBorkar/budget_max/controllers"
 	"github.com/AlsoShantanuBorkar/budget_max/database"
 	"github.com/AlsoShantanuBorkar/budget_max/middleware"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
-func RegisterTransactionRoutes(rg *gin.RouterGroup, ctrl controllers.TransactionControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface) {
+func RegisterTransactionRoutes(rg *gin.RouterGroup, ctrl controllers.TransactionControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface, userDatabaseService database.UserDatabaseServiceInterface, appConfig *config.AppConfig, redisClient *redis.Client) {
 	transaction := rg.Group("/transaction")
 
-	transaction.Use(middleware.AuthMiddleware(sessionDatabaseService))
+	transaction.Use(middleware.AuthMiddleware(sessionDatabaseService, appConfig, redisClient))
+	transaction.Use(middleware.VerifiedEmailMiddleware(userDatabaseService, appConfig))
 
 	transaction.GET("/", ctrl.GetTransactionsByUserID)
 	transaction.POST("/", ctrl.CreateTransaction)
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/AlsoShantanuBorkar/budget_max/config"
+	"github.com/AlsoShantanuBorkar/budget_max/controllers"
+	"github.com/AlsoShantanuBorkar/budget_max/database"
+	"github.com/AlsoShantanuBorkar/budget_max/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+func RegisterUnprotectedUserRoutes(rg *gin.RouterGroup, ctrl controllers.UserControllerInterface) {
+	me := rg.Group("/me")
+
+	// The confirmation link may be opened on a device that is not signed in
+	me.POST("/email/confirm", ctrl.ConfirmEmailChange)
+}
+
+func RegisterUserRoutes(rg *gin.RouterGroup, ctrl controllers.UserControllerInterface, sessionDatabaseService database.SessionDatabaseServiceInterface, appConfig *config.AppConfig, redisClient *redis.Client) {
+	me := rg.Group("/me")
+
+	me.Use(middleware.AuthMiddleware(sessionDatabaseService, appConfig, redisClient))
+	me.GET("", ctrl.GetProfile)
+	me.PATCH("", ctrl.UpdateProfile)
+
+	// Changing the password or email and deleting the account need a recent POST /auth/reauth on
+	// the current session, on top of the password the first two also ask for
+	sensitive := me.Group("")
+	sensitive.Use(middleware.ReauthMiddleware(redisClient))
+	sensitive.POST("/password", ctrl.ChangePassword)
+	sensitive.POST("/email", ctrl.RequestEmailChange)
+	sensitive.DELETE("", ctrl.DeleteAccount)
+}
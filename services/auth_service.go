@@ -1,11 +1,14 @@
 package services
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/AlsoShantanuBorkar/budget_max/config"
 	"github.com/AlsoShantanuBorkar/budget_max/database"
 	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/mailer"
 	"github.com/AlsoShantanuBorkar/budget_max/models"
 	"github.com/AlsoShantanuBorkar/budget_max/utils"
 	"github.com/gin-gonic/gin"
@@ -14,18 +17,26 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// PasswordResetTokenTTL is how long an emailed password reset link stays valid
+const PasswordResetTokenTTL = time.Hour
+
+// EmailVerificationTokenTTL is how long the link sent after signup stays valid
+const EmailVerificationTokenTTL = 24 * time.Hour
+
 type LoginResponse struct {
 	Session     uuid.UUID `json:"session"`
 	Refresh     uuid.UUID `json:"refresh"`
 	UserID      uuid.UUID `json:"user_id"`
 	Requires2FA bool      `json:"requires_2fa"`
 	Token       string    `json:"token,omitempty"`
+	AccessToken string    `json:"access_token,omitempty"`
 }
 
 type RefreshResponse struct {
-	Session uuid.UUID `json:"session"`
-	Refresh uuid.UUID `json:"refresh"`
-	UserID  uuid.UUID `json:"user_id"`
+	Session     uuid.UUID `json:"session"`
+	Refresh     uuid.UUID `json:"refresh"`
+	UserID      uuid.UUID `json:"user_id"`
+	AccessToken string    `json:"access_token,omitempty"`
 }
 
 type TwoFAGenerateResponse struct {
@@ -36,9 +47,23 @@ type TwoFAGenerateResponse struct {
 }
 
 type TwoFALoginResponse struct {
-	Session uuid.UUID `json:"session"`
-	Refresh uuid.UUID `json:"refresh"`
-	UserID  uuid.UUID `json:"user_id"`
+	Session     uuid.UUID `json:"session"`
+	Refresh     uuid.UUID `json:"refresh"`
+	UserID      uuid.UUID `json:"user_id"`
+	AccessToken string    `json:"access_token,omitempty"`
+}
+
+type SessionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	IPAddress string    `json:"ip_address"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Current   bool      `json:"current"`
+}
+
+type ReauthResponse struct {
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type AuthServiceInterface interface {
@@ -47,26 +72,46 @@ type AuthServiceInterface interface {
 	Logout(c *gin.Context, sessionTokenStr string) *ServiceError
 	RefreshToken(c *gin.Context, req *models.RefreshTokensRequest) (*RefreshResponse, *ServiceError)
 	Generate2FA(c *gin.Context, userId uuid.UUID) (*TwoFAGenerateResponse, *ServiceError)
-	Verify2FA(c *gin.Context, req *models.TwoFactorVerifyRequest, userId uuid.UUID) *ServiceError
+	Verify2FA(c *gin.Context, req *models.TwoFactorVerifyRequest, userId uuid.UUID) ([]string, *ServiceError)
 	Disable2FA(c *gin.Context, userId uuid.UUID) *ServiceError
 	LoginWith2FA(c *gin.Context, req *models.TwoFactorLoginRequest) (*TwoFALoginResponse, *ServiceError)
+	ForgotPassword(c *gin.Context, req *models.ForgotPasswordRequest) *ServiceError
+	ResetPassword(c *gin.Context, req *models.ResetPasswordRequest) *ServiceError
+	GetSessions(c *gin.Context, userId uuid.UUID, currentSessionId uuid.UUID) ([]*SessionResponse, *ServiceError)
+	RevokeSession(c *gin.Context, userId uuid.UUID, sessionId uuid.UUID) *ServiceError
+	RevokeOtherSessions(c *gin.Context, userId uuid.UUID, currentSessionId uuid.UUID) (int, *ServiceError)
+	RegenerateRecoveryCodes(c *gin.Context, req *models.TwoFactorVerifyRequest, userId uuid.UUID) ([]string, *ServiceError)
+	GetRecoveryCodesCount(c *gin.Context, userId uuid.UUID) (int64, *ServiceError)
+	Reauthenticate(c *gin.Context, req *models.ReauthRequest, userId uuid.UUID, sessionId uuid.UUID) (*ReauthResponse, *ServiceError)
+	RevokeAllSessions(c *gin.Context, userId uuid.UUID) *ServiceError
+	VerifyEmail(c *gin.Context, req *models.VerifyEmailRequest) *ServiceError
+	ResendVerificationEmail(c *gin.Context, req *models.ResendVerificationRequest) *ServiceError
 }
 
+
 type AuthService struct {
-	userDatabaseService         database.UserDatabaseServiceInterface
-	sessionDatabaseService      database.SessionDatabaseServiceInterface
-	refreshTokenDatabaseService database.RefreshTokenDatabaseServiceInterface
-	config                      *config.AppConfig
-	redisClient                 *redis.Client
+	userDatabaseService                   database.UserDatabaseServiceInterface
+	sessionDatabaseService                database.SessionDatabaseServiceInterface
+	refreshTokenDatabaseService           database.RefreshTokenDatabaseServiceInterface
+	passwordResetTokenDatabaseService     database.PasswordResetTokenDatabaseServiceInterface
+	recoveryCodeDatabaseService           database.RecoveryCodeDatabaseServiceInterface
+	emailVerificationTokenDatabaseService database.EmailVerificationTokenDatabaseServiceInterface
+	mailer                                mailer.Mailer
+	config                                *config.AppConfig
+	redisClient                           *redis.Client
 }
 
-func NewAuthService(userDBService database.UserDatabaseServiceInterface, sessionDBService database.SessionDatabaseServiceInterface, refreshTokenDBService database.RefreshTokenDatabaseServiceInterface, config *config.AppConfig, redisClient *redis.Client) *AuthService {
+func NewAuthService(userDBService database.UserDatabaseServiceInterface, sessionDBService database.SessionDatabaseServiceInterface, refreshTokenDBService database.RefreshTokenDatabaseServiceInterface, passwordResetTokenDBService database.PasswordResetTokenDatabaseServiceInterface, recoveryCodeDBService database.RecoveryCodeDatabaseServiceInterface, emailVerificationTokenDBService database.EmailVerificationTokenDatabaseServiceInterface, mailer mailer.Mailer, config *config.AppConfig, redisClient *redis.Client) *AuthService {
 	return &AuthService{
-		userDatabaseService:         userDBService,
-		sessionDatabaseService:      sessionDBService,
-		refreshTokenDatabaseService: refreshTokenDBService,
-		config:                      config,
-		redisClient:                 redisClient,
+		userDatabaseService:                   userDBService,
+		sessionDatabaseService:                sessionDBService,
+		refreshTokenDatabaseService:           refreshTokenDBService,
+		passwordResetTokenDatabaseService:     passwordResetTokenDBService,
+		recoveryCodeDatabaseService:           recoveryCodeDBService,
+		emailVerificationTokenDatabaseService: emailVerificationTokenDBService,
+		mailer:                                mailer,
+		config:                                config,
+		redisClient:                           redisClient,
 	}
 }
 
@@ -107,6 +152,11 @@ func (s *AuthService) Signup(c *gin.Context, req *models.AuthRequest) *ServiceEr
 	       return nil
        }
 
+       // The account exists either way, a failed delivery can be retried via the resend endpoint
+       if err := s.sendVerificationEmail(c, &user); err != nil {
+	       utils.GetLogger().Error().Err(err).Str("user_id", user.ID.String()).Msg("Failed to send verification email")
+       }
+
        return nil
 }
 
@@ -137,8 +187,17 @@ func (s *AuthService) Login(c *gin.Context, req *models.AuthRequest) (*LoginResp
 	       return nil, nil
        }
 
-       // Reset login attempts on successful login
-	utils.ResetLoginAttempts(req.Email, s.redisClient, c.Request.Context())
+       // Reset login attempts on successful login. With 2FA on, that waits for the second factor so
+       // a known password cannot clear the count of wrong codes.
+	if !user.TwoFactorEnabled {
+		utils.ResetLoginAttempts(req.Email, s.redisClient, c.Request.Context())
+	}
+
+	if s.config.EmailVerificationPolicy == config.EmailVerificationBlock && !user.EmailVerified {
+		appErr := errors.NewForbiddenError("email address is not verified", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
 
        // Check if 2FA is enabled
        if user.TwoFactorEnabled {
@@ -156,18 +215,26 @@ func (s *AuthService) Login(c *gin.Context, req *models.AuthRequest) (*LoginResp
        }
 
        // Create session and refresh token
-       session, refresh, err := utils.CreateSessionAndRefreshToken(user, c.ClientIP(), c.Request.UserAgent(), s.sessionDatabaseService, s.refreshTokenDatabaseService, c)
+       session, refresh, err := utils.CreateSessionAndRefreshToken(user, c.ClientIP(), c.Request.UserAgent(), nil, s.sessionDatabaseService, s.refreshTokenDatabaseService, c)
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, nil
        }
 
+       accessToken, err := s.issueAccessToken(user, &session)
+       if err != nil {
+	       appErr := errors.NewInternalError(err)
+	       c.Error(appErr)
+	       return nil, ServiceErrorFromAppError(appErr)
+       }
+
        return &LoginResponse{
 	       Session:     session.Token,
 	       Refresh:     refresh.Token,
 	       UserID:      user.ID,
 	       Requires2FA: false,
+	       AccessToken: accessToken,
        }, nil
 }
 
@@ -178,6 +245,10 @@ func (s *AuthService) Logout(c *gin.Context, sessionTokenStr string) *ServiceErr
 	       return nil
        }
 
+       if s.config.AuthMode == config.AuthModeJWT {
+	       return s.logoutAccessToken(c, strings.TrimPrefix(sessionTokenStr, "Bearer "))
+       }
+
        sessionToken, err := uuid.Parse(sessionTokenStr)
        if err != nil {
 	       appErr := errors.NewUnauthorizedError("unauthorized", err, )
@@ -219,19 +290,47 @@ func (s *AuthService) RefreshToken(c *gin.Context, req *models.RefreshTokensRequ
 
        // Get refresh token from database
        token, err := s.refreshTokenDatabaseService.GetRefreshTokenByToken(refreshToken)
-       if err != nil || token == nil || token.Revoked || token.ExpiresAt.Before(time.Now()) {
+       if err != nil || token == nil {
 	       appErr := errors.NewUnauthorizedError("invalid or expired refresh token", err, )
 	       c.Error(appErr)
 	       return nil, nil
        }
 
-       // Revoke the old refresh token
-       if err := s.refreshTokenDatabaseService.RevokeRefreshToken(token.ID); err != nil {
+       // A revoked token being presented again means it was copied; burn the whole family
+       if token.Revoked {
+	       return nil, s.handleRefreshTokenReuse(c, token)
+       }
+
+       if token.ExpiresAt.Before(time.Now()) {
+	       appErr := errors.NewUnauthorizedError("invalid or expired refresh token", nil, )
+	       c.Error(appErr)
+	       return nil, nil
+       }
+
+       // Revoke the old refresh token. Losing this race to a concurrent request is also a replay.
+       rotated, err := s.refreshTokenDatabaseService.RevokeRefreshTokenIfActive(token.ID)
+       if err != nil {
+	       appErr := errors.NewInternalError(err, )
+	       c.Error(appErr)
+	       return nil, nil
+       }
+       if !rotated {
+	       return nil, s.handleRefreshTokenReuse(c, token)
+       }
+
+       // The rotated session is replaced by the one created below
+       if err := s.sessionDatabaseService.RevokeSession(token.SessionID); err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, nil
        }
 
+       if err := s.revokeAccessTokens(c, token.SessionID); err != nil {
+	       appErr := errors.NewInternalError(err)
+	       c.Error(appErr)
+	       return nil, ServiceErrorFromAppError(appErr)
+       }
+
        // Get user details
        user, err := s.userDatabaseService.GetUserByID(token.UserID)
        if err != nil {
@@ -241,17 +340,25 @@ func (s *AuthService) RefreshToken(c *gin.Context, req *models.RefreshTokensRequ
        }
 
        // Create new session and refresh token
-       session, refresh, err := utils.CreateSessionAndRefreshToken(user, c.ClientIP(), c.Request.UserAgent(), s.sessionDatabaseService, s.refreshTokenDatabaseService, c)
+       session, refresh, err := utils.CreateSessionAndRefreshToken(user, c.ClientIP(), c.Request.UserAgent(), token, s.sessionDatabaseService, s.refreshTokenDatabaseService, c)
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, nil
        }
 
+       accessToken, err := s.issueAccessToken(user, &session)
+       if err != nil {
+	       appErr := errors.NewInternalError(err)
+	       c.Error(appErr)
+	       return nil, ServiceErrorFromAppError(appErr)
+       }
+
        return &RefreshResponse{
-	       Session: session.Token,
-	       Refresh: refresh.Token,
-	       UserID:  user.ID,
+	       Session:     session.Token,
+	       Refresh:     refresh.Token,
+	       UserID:      user.ID,
+	       AccessToken: accessToken,
        }, nil
 }
 
@@ -301,19 +408,25 @@ func (s *AuthService) Generate2FA(c *gin.Context, userId uuid.UUID) (*TwoFAGener
        }, nil
 }
 
-func (s *AuthService) Verify2FA(c *gin.Context, req *models.TwoFactorVerifyRequest, userId uuid.UUID) *ServiceError {
+func (s *AuthService) Verify2FA(c *gin.Context, req *models.TwoFactorVerifyRequest, userId uuid.UUID) ([]string, *ServiceError) {
        user, err := s.userDatabaseService.GetUserByID(userId)
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
-	       return nil
+	       return nil, ServiceErrorFromAppError(appErr)
+       }
+
+       if user.TwoFactorEnabled {
+	       appErr := errors.NewBadRequestError("2FA is already enabled", nil, )
+	       c.Error(appErr)
+	       return nil, ServiceErrorFromAppError(appErr)
        }
 
        valid := totp.Validate(req.Code, user.TwoFactorSecret)
        if !valid {
 	       appErr := errors.NewUnauthorizedError("invalid 2FA code", nil, )
 	       c.Error(appErr)
-	       return nil
+	       return nil, ServiceErrorFromAppError(appErr)
        }
 
        user.TwoFactorEnabled = true
@@ -325,10 +438,18 @@ func (s *AuthService) Verify2FA(c *gin.Context, req *models.TwoFactorVerifyReque
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
-	       return nil
+	       return nil, ServiceErrorFromAppError(appErr)
        }
 
-       return nil
+       // Hand out recovery codes once, they are only stored hashed
+       codes, err := s.issueRecoveryCodes(userId)
+       if err != nil {
+	       appErr := errors.NewInternalError(err, )
+	       c.Error(appErr)
+	       return nil, ServiceErrorFromAppError(appErr)
+       }
+
+       return codes, nil
 }
 
 func (s *AuthService) Disable2FA(c *gin.Context, userId uuid.UUID) *ServiceError {
@@ -358,6 +479,12 @@ func (s *AuthService) Disable2FA(c *gin.Context, userId uuid.UUID) *ServiceError
 	       return nil
        }
 
+       if err := s.recoveryCodeDatabaseService.DeleteRecoveryCodesByUserID(userId); err != nil {
+	       appErr := errors.NewInternalError(err, )
+	       c.Error(appErr)
+	       return nil
+       }
+
        return nil
 }
 
@@ -395,24 +522,591 @@ func (s *AuthService) LoginWith2FA(c *gin.Context, req *models.TwoFactorLoginReq
 	       return nil, nil
        }
 
-       valid := totp.Validate(req.Code, user.TwoFactorSecret)
-       if !valid {
-	       appErr := errors.NewUnauthorizedError("invalid 2FA code", nil, )
-	       c.Error(appErr)
-	       return nil, nil
+	if serviceErr := s.checkLoginLock(c, user.Email); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+       if req.RecoveryCode != "" {
+	       // Recovery codes are single use; the conditional update makes consumption atomic
+	       consumed, err := s.recoveryCodeDatabaseService.ConsumeRecoveryCode(user.ID, utils.HashRecoveryCode(req.RecoveryCode))
+	       if err != nil {
+		       appErr := errors.NewInternalError(err, )
+		       c.Error(appErr)
+		       return nil, ServiceErrorFromAppError(appErr)
+	       }
+	       if !consumed {
+		       return nil, s.failedSecondFactor(c, user.Email, "invalid recovery code")
+	       }
+       } else {
+	       valid := totp.Validate(req.Code, user.TwoFactorSecret)
+	       if !valid {
+		       return nil, s.failedSecondFactor(c, user.Email, "invalid 2FA code")
+	       }
        }
 
+	utils.ResetLoginAttempts(user.Email, s.redisClient, c.Request.Context())
+
        // Create session and refresh token
-       session, refresh, err := utils.CreateSessionAndRefreshToken(user, c.ClientIP(), c.Request.UserAgent(), s.sessionDatabaseService, s.refreshTokenDatabaseService, c)
+       session, refresh, err := utils.CreateSessionAndRefreshToken(user, c.ClientIP(), c.Request.UserAgent(), nil, s.sessionDatabaseService, s.refreshTokenDatabaseService, c)
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, nil
        }
 
+       accessToken, err := s.issueAccessToken(user, &session)
+       if err != nil {
+	       appErr := errors.NewInternalError(err)
+	       c.Error(appErr)
+	       return nil, ServiceErrorFromAppError(appErr)
+       }
+
        return &TwoFALoginResponse{
-	       Session: session.Token,
-	       Refresh: refresh.Token,
-	       UserID:  user.ID,
+	       Session:     session.Token,
+	       Refresh:     refresh.Token,
+	       UserID:      user.ID,
+	       AccessToken: accessToken,
        }, nil
 }
+
+// ForgotPassword emails a single use reset link. It never reveals whether the email is registered.
+func (s *AuthService) ForgotPassword(c *gin.Context, req *models.ForgotPasswordRequest) *ServiceError {
+	user, err := s.userDatabaseService.GetUserByEmail(req.Email)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if user == nil {
+		return nil
+	}
+
+	// Only the most recent link should work
+	if err := s.passwordResetTokenDatabaseService.InvalidatePasswordResetTokensByUserID(user.ID); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	rawToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	now := time.Now()
+	resetToken := models.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Token:     utils.HashToken(rawToken),
+		ExpiresAt: now.Add(PasswordResetTokenTTL),
+		CreatedAt: now,
+	}
+
+	if err := s.passwordResetTokenDatabaseService.CreatePasswordResetToken(&resetToken); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	err = s.mailer.Send(c.Request.Context(), mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your BudgetMax password",
+		Body: fmt.Sprintf("We received a request to reset your password.\n\nUse the link below within %d minutes to choose a new one:\n%s/reset-password?token=%s\n\nIf you did not request this, you can ignore this email.",
+			int(PasswordResetTokenTTL.Minutes()), s.config.AppURL, rawToken),
+	})
+	if err != nil {
+		// Failing here only for registered addresses would reveal which ones have an account
+		utils.GetLogger().Error().Err(err).Str("user_id", user.ID.String()).Msg("Failed to send password reset email")
+	}
+
+	return nil
+}
+
+// ResetPassword consumes a reset token, sets the new password and signs the user out everywhere
+func (s *AuthService) ResetPassword(c *gin.Context, req *models.ResetPasswordRequest) *ServiceError {
+	resetToken, err := s.passwordResetTokenDatabaseService.GetPasswordResetTokenByToken(utils.HashToken(req.Token))
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if resetToken == nil || resetToken.Used || resetToken.ExpiresAt.Before(time.Now()) {
+		appErr := errors.NewBadRequestError("invalid or expired reset token", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	consumed, err := s.passwordResetTokenDatabaseService.MarkPasswordResetTokenUsed(resetToken.ID)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if !consumed {
+		appErr := errors.NewBadRequestError("invalid or expired reset token", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if err := s.userDatabaseService.UpdateUser(resetToken.UserID, map[string]any{
+		"password": hashedPassword,
+	}); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	// Sign the user out of every device
+	return s.RevokeAllSessions(c, resetToken.UserID)
+}
+
+// RevokeAllSessions signs the user out of every device, including any issued access tokens
+func (s *AuthService) RevokeAllSessions(c *gin.Context, userId uuid.UUID) *ServiceError {
+	if err := s.revokeAllAccessTokens(c, userId); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if err := s.sessionDatabaseService.RevokeSessionsByUserID(userId); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if err := s.refreshTokenDatabaseService.RevokeRefreshTokensByUserID(userId); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	return nil
+}
+
+// GetSessions lists the devices the user is signed in on and flags the one making the request
+func (s *AuthService) GetSessions(c *gin.Context, userId uuid.UUID, currentSessionId uuid.UUID) ([]*SessionResponse, *ServiceError) {
+	sessions, err := s.sessionDatabaseService.GetActiveSessionsByUserID(userId)
+	if err != nil {
+		appErr := errors.NewDBError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	response := make([]*SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, &SessionResponse{
+			ID:        session.ID,
+			UserAgent: session.UserAgent,
+			IPAddress: session.IPAddress,
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpiresAt,
+			Current:   session.ID == currentSessionId,
+		})
+	}
+
+	return response, nil
+}
+
+// RevokeSession signs out a single session owned by the user along with its refresh tokens
+func (s *AuthService) RevokeSession(c *gin.Context, userId uuid.UUID, sessionId uuid.UUID) *ServiceError {
+	session, err := s.sessionDatabaseService.GetSessionByID(sessionId, userId)
+	if err != nil || session == nil {
+		appErr := errors.NewNotFoundError("session", err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if err := s.revokeSessionAndRefreshTokens(c, session.ID); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions signs out every session except the current one and returns how many were revoked
+func (s *AuthService) RevokeOtherSessions(c *gin.Context, userId uuid.UUID, currentSessionId uuid.UUID) (int, *ServiceError) {
+	sessions, err := s.sessionDatabaseService.GetActiveSessionsByUserID(userId)
+	if err != nil {
+		appErr := errors.NewDBError(err)
+		c.Error(appErr)
+		return 0, ServiceErrorFromAppError(appErr)
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == currentSessionId {
+			continue
+		}
+		if err := s.revokeSessionAndRefreshTokens(c, session.ID); err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return revoked, ServiceErrorFromAppError(appErr)
+		}
+		revoked++
+	}
+
+	return revoked, nil
+}
+
+func (s *AuthService) revokeSessionAndRefreshTokens(c *gin.Context, sessionId uuid.UUID) error {
+	if err := s.sessionDatabaseService.RevokeSession(sessionId); err != nil {
+		return err
+	}
+	if err := s.refreshTokenDatabaseService.RevokeRefreshTokensBySessionID(sessionId); err != nil {
+		return err
+	}
+	return s.revokeAccessTokens(c, sessionId)
+}
+
+// issueAccessToken signs a stateless access token for the session in JWT mode. In session
+// mode clients keep using the opaque session token and no access token is returned.
+func (s *AuthService) issueAccessToken(user *models.User, session *models.Session) (string, error) {
+	if s.config.AuthMode != config.AuthModeJWT {
+		return "", nil
+	}
+	return utils.GenerateAccessJWT(user, session, s.config)
+}
+
+// revokeAccessTokens puts the sessions on the Redis revocation list so their already issued
+// access tokens stop working. Opaque session tokens are checked against the database instead.
+func (s *AuthService) revokeAccessTokens(c *gin.Context, sessionIds ...uuid.UUID) error {
+	if s.config.AuthMode != config.AuthModeJWT {
+		return nil
+	}
+	for _, sessionId := range sessionIds {
+		if err := utils.RevokeSessionAccessTokens(sessionId, s.redisClient, c.Request.Context()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeAllAccessTokens revokes the access tokens of every active session of the user
+func (s *AuthService) revokeAllAccessTokens(c *gin.Context, userId uuid.UUID) error {
+	if s.config.AuthMode != config.AuthModeJWT {
+		return nil
+	}
+	sessions, err := s.sessionDatabaseService.GetActiveSessionsByUserID(userId)
+	if err != nil {
+		return err
+	}
+	sessionIds := make([]uuid.UUID, 0, len(sessions))
+	for _, session := range sessions {
+		sessionIds = append(sessionIds, session.ID)
+	}
+	return s.revokeAccessTokens(c, sessionIds...)
+}
+
+// logoutAccessToken ends the session an access token was issued for
+func (s *AuthService) logoutAccessToken(c *gin.Context, tokenStr string) *ServiceError {
+	claims, err := utils.VerifyAccessJWT(tokenStr, s.config)
+	if err != nil {
+		appErr := errors.NewUnauthorizedError("unauthorized", err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if err := s.revokeSessionAndRefreshTokens(c, claims.SessionID); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	return nil
+}
+
+// handleRefreshTokenReuse revokes every refresh token and session descended from the same
+// login as the replayed token and records a security event.
+func (s *AuthService) handleRefreshTokenReuse(c *gin.Context, token *models.RefreshToken) *ServiceError {
+	logger := utils.GetLogger()
+	logger.Warn().
+		Str("event", "refresh_token_reuse").
+		Str("request_id", c.GetString("request_id")).
+		Str("user_id", token.UserID.String()).
+		Str("family_id", token.FamilyID.String()).
+		Str("token_id", token.ID.String()).
+		Str("ip_address", c.ClientIP()).
+		Str("user_agent", c.Request.UserAgent()).
+		Msg("Revoked refresh token was presented again, revoking token family")
+
+	family, err := s.refreshTokenDatabaseService.GetRefreshTokensByFamilyID(token.FamilyID)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if err := s.refreshTokenDatabaseService.RevokeRefreshTokensByFamilyID(token.FamilyID); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	revokedSessions := make(map[uuid.UUID]bool)
+	for _, member := range family {
+		if revokedSessions[member.SessionID] {
+			continue
+		}
+		if err := s.sessionDatabaseService.RevokeSession(member.SessionID); err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return ServiceErrorFromAppError(appErr)
+		}
+		if err := s.revokeAccessTokens(c, member.SessionID); err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return ServiceErrorFromAppError(appErr)
+		}
+		revokedSessions[member.SessionID] = true
+	}
+
+	appErr := errors.NewUnauthorizedError("refresh token reuse detected", nil)
+	c.Error(appErr)
+	return ServiceErrorFromAppError(appErr)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user after checking a current TOTP code
+func (s *AuthService) RegenerateRecoveryCodes(c *gin.Context, req *models.TwoFactorVerifyRequest, userId uuid.UUID) ([]string, *ServiceError) {
+	user, err := s.userDatabaseService.GetUserByID(userId)
+	if err != nil || user == nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	if !user.TwoFactorEnabled {
+		appErr := errors.NewBadRequestError("2FA is not enabled", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	if !totp.Validate(req.Code, user.TwoFactorSecret) {
+		appErr := errors.NewUnauthorizedError("invalid 2FA code", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	codes, err := s.issueRecoveryCodes(userId)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return codes, nil
+}
+
+func (s *AuthService) GetRecoveryCodesCount(c *gin.Context, userId uuid.UUID) (int64, *ServiceError) {
+	count, err := s.recoveryCodeDatabaseService.CountUnusedRecoveryCodes(userId)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return 0, ServiceErrorFromAppError(appErr)
+	}
+
+	return count, nil
+}
+
+// issueRecoveryCodes generates a fresh set of codes, replaces the stored hashes and returns the plain codes
+func (s *AuthService) issueRecoveryCodes(userId uuid.UUID) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes(utils.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	records := make([]models.RecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		records = append(records, models.RecoveryCode{
+			ID:        uuid.New(),
+			UserID:    userId,
+			CodeHash:  utils.HashRecoveryCode(code),
+			CreatedAt: now,
+		})
+	}
+
+	if err := s.recoveryCodeDatabaseService.ReplaceRecoveryCodes(userId, records); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Reauthenticate checks the password (and TOTP code when 2FA is on) of the signed in user and
+// unlocks sensitive operations for the current session for utils.ReauthWindow.
+func (s *AuthService) Reauthenticate(c *gin.Context, req *models.ReauthRequest, userId uuid.UUID, sessionId uuid.UUID) (*ReauthResponse, *ServiceError) {
+	user, err := s.userDatabaseService.GetUserByID(userId)
+	if err != nil || user == nil {
+		appErr := errors.NewUnauthorizedError("unauthorized", err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	if err := utils.CheckPasswordHash(req.Password, user.Password); err != nil {
+		// Re-auth failures count towards the same lockout as login failures
+		if trackErr := utils.CheckAndTrackLoginAttempts(user.Email, s.redisClient, c.Request.Context()); trackErr != nil {
+			appErr := errors.NewTooManyRequestsError(trackErr.Error(), trackErr)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		appErr := errors.NewUnauthorizedError("invalid password", err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	if user.TwoFactorEnabled {
+		if serviceErr := s.checkLoginLock(c, user.Email); serviceErr != nil {
+			return nil, serviceErr
+		}
+		if req.Code == "" || !totp.Validate(req.Code, user.TwoFactorSecret) {
+			return nil, s.failedSecondFactor(c, user.Email, "invalid 2FA code")
+		}
+	}
+
+	utils.ResetLoginAttempts(user.Email, s.redisClient, c.Request.Context())
+
+	expiresAt, err := utils.MarkSessionReauthenticated(sessionId, s.redisClient, c.Request.Context())
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return &ReauthResponse{ExpiresAt: expiresAt}, nil
+}
+
+// VerifyEmail consumes a verification token and marks the address of its user as verified
+func (s *AuthService) VerifyEmail(c *gin.Context, req *models.VerifyEmailRequest) *ServiceError {
+	verificationToken, err := s.emailVerificationTokenDatabaseService.GetEmailVerificationTokenByToken(utils.HashToken(req.Token))
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if verificationToken == nil || verificationToken.Used || verificationToken.ExpiresAt.Before(time.Now()) {
+		appErr := errors.NewBadRequestError("invalid or expired verification token", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	consumed, err := s.emailVerificationTokenDatabaseService.MarkEmailVerificationTokenUsed(verificationToken.ID)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if !consumed {
+		appErr := errors.NewBadRequestError("invalid or expired verification token", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if err := s.userDatabaseService.UpdateUser(verificationToken.UserID, map[string]any{
+		"email_verified":    true,
+		"email_verified_at": time.Now(),
+	}); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	return nil
+}
+
+// ResendVerificationEmail sends a fresh verification link. It never reveals whether the email is registered.
+func (s *AuthService) ResendVerificationEmail(c *gin.Context, req *models.ResendVerificationRequest) *ServiceError {
+	user, err := s.userDatabaseService.GetUserByEmail(req.Email)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if user == nil || user.EmailVerified {
+		return nil
+	}
+
+	if err := s.sendVerificationEmail(c, user); err != nil {
+		// Failing here only for registered addresses would reveal which ones have an account
+		utils.GetLogger().Error().Err(err).Str("user_id", user.ID.String()).Msg("Failed to send verification email")
+	}
+
+	return nil
+}
+
+// checkLoginLock refuses second factor codes while failed attempts have the account locked, so the
+// lockout also stops codes from being guessed
+func (s *AuthService) checkLoginLock(c *gin.Context, email string) *ServiceError {
+	locked, err := utils.IsLoginLocked(email, s.redisClient, c.Request.Context())
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+	if locked {
+		appErr := errors.NewTooManyRequestsError("account is locked due to too many login attempts", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+	return nil
+}
+
+// failedSecondFactor counts a wrong TOTP or recovery code towards the same lockout as wrong passwords
+func (s *AuthService) failedSecondFactor(c *gin.Context, email string, message string) *ServiceError {
+	if trackErr := utils.CheckAndTrackLoginAttempts(email, s.redisClient, c.Request.Context()); trackErr != nil {
+		appErr := errors.NewTooManyRequestsError(trackErr.Error(), trackErr)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+	appErr := errors.NewUnauthorizedError(message, nil)
+	c.Error(appErr)
+	return ServiceErrorFromAppError(appErr)
+}
+
+// sendVerificationEmail replaces any outstanding verification token of the user and emails the new link
+func (s *AuthService) sendVerificationEmail(c *gin.Context, user *models.User) error {
+	if err := s.emailVerificationTokenDatabaseService.InvalidateEmailVerificationTokensByUserID(user.ID); err != nil {
+		return err
+	}
+
+	rawToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	verificationToken := models.EmailVerificationToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Token:     utils.HashToken(rawToken),
+		ExpiresAt: now.Add(EmailVerificationTokenTTL),
+		CreatedAt: now,
+	}
+
+	if err := s.emailVerificationTokenDatabaseService.CreateEmailVerificationToken(&verificationToken); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(c.Request.Context(), mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your BudgetMax email address",
+		Body: fmt.Sprintf("Welcome to BudgetMax!\n\nUse the link below within %d hours to verify your email address:\n%s/verify-email?token=%s\n\nIf you did not sign up, you can ignore this email.",
+			int(EmailVerificationTokenTTL.Hours()), s.config.AppURL, rawToken),
+	})
+}
@@ -1,11 +1,16 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
 	"time"
 
+	"github.com/AlsoShantanuBorkar/budget_max/config"
 	"github.com/AlsoShantanuBorkar/budget_max/database"
 	"github.com/AlsoShantanuBorkar/budget_max/errors"
 	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/AlsoShantanuBorkar/budget_max/models/budget"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -13,115 +18,272 @@ import (
 type BudgetServiceInterface interface {
 	CreateBudget(c *gin.Context, req *models.CreateBudgetRequest, userId uuid.UUID) (*models.Budget, *ServiceError)
 	UpdateBudget(c *gin.Context, req *models.UpdateBudgetRequest, budgetId uuid.UUID, userId uuid.UUID) (*models.Budget, *ServiceError)
-	DeleteBudget(c *gin.Context, budgetId uuid.UUID, userId uuid.UUID) *ServiceError
-	GetBudgetsByUserID(c *gin.Context, userId uuid.UUID) ([]models.Budget, *ServiceError)
+	DeleteBudget(c *gin.Context, req *models.DeleteBudgetRequest, budgetId uuid.UUID, userId uuid.UUID) *ServiceError
+	GetBudgetsByUserID(c *gin.Context, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[models.Budget], *ServiceError)
 	GetBudgetByID(c *gin.Context, budgetID uuid.UUID, userId uuid.UUID) (*models.Budget, *ServiceError)
+	RollOverDue(now time.Time) (int, error)
+}
+
+// rolloverBatchSize caps how many budgets one rollover run works through
+const rolloverBatchSize = 100
+
+var budgetPages = pageSpec[models.Budget]{
+	keys: map[string]sortKey[models.Budget]{
+		"start_date": {column: "start_date", value: func(b models.Budget) any { return b.StartDate }, decode: decodeCursorValue[time.Time]},
+		"end_date":   {column: "end_date", value: func(b models.Budget) any { return b.EndDate }, decode: decodeCursorValue[time.Time]},
+		"amount":     {column: "amount", value: func(b models.Budget) any { return b.Amount }, decode: decodeCursorValue[models.Money]},
+		"name":       {column: "name", value: func(b models.Budget) any { return b.Name }, decode: decodeCursorValue[string]},
+		"created_at": {column: "created_at", value: func(b models.Budget) any { return b.CreatedAt }, decode: decodeCursorValue[time.Time]},
+	},
+	defaultSort: "-start_date",
+	id:          func(b models.Budget) uuid.UUID { return b.ID },
 }
 
 type BudgetService struct {
-	databaseService database.BudgetDatabaseServiceInterface
+	databaseService     database.BudgetDatabaseServiceInterface
+	transactionDatabase database.TransactionDatabaseServiceInterface
+	categoryDatabase    database.CategoryDatabaseServiceInterface
+	userDatabase        database.UserDatabaseServiceInterface
+	exchangeRates       ExchangeRateServiceInterface
+	config              *config.AppConfig
+}
+
+// StartBudgetRolloverScheduler moves recurring budgets into their next period right away and then every interval.
+// A zero interval disables the scheduler.
+func StartBudgetRolloverScheduler(service BudgetServiceInterface, interval time.Duration) {
+	startScheduler(interval, func(now time.Time) {
+		rolled, err := service.RollOverDue(now)
+		if err != nil {
+			utils.GetLogger().Error().Err(err).Msg("Failed to roll over budgets")
+		}
+		if rolled > 0 {
+			utils.GetLogger().Info().Int("periods", rolled).Msg("Rolled over budgets")
+		}
+	})
 }
 
-func NewBudgetService(dbService database.BudgetDatabaseServiceInterface) BudgetServiceInterface {
-	return &BudgetService{databaseService: dbService}
+func NewBudgetService(dbService database.BudgetDatabaseServiceInterface, transactionDBService database.TransactionDatabaseServiceInterface, categoryDBService database.CategoryDatabaseServiceInterface, userDBService database.UserDatabaseServiceInterface, exchangeRates ExchangeRateServiceInterface, config *config.AppConfig) BudgetServiceInterface {
+	return &BudgetService{
+		databaseService:     dbService,
+		transactionDatabase: transactionDBService,
+		categoryDatabase:    categoryDBService,
+		userDatabase:        userDBService,
+		exchangeRates:       exchangeRates,
+		config:              config,
+	}
 }
 
 func (s *BudgetService) CreateBudget(c *gin.Context, req *models.CreateBudgetRequest, userId uuid.UUID) (*models.Budget, *ServiceError) {
-       budget := &models.Budget{
-              ID:        uuid.New(),
-              UserID:    userId,
-              Type:      req.Type,
-              Name:      req.Name,
-              StartDate: req.StartDate,
-              EndDate:   req.EndDate,
-              Amount:    req.Amount,
-              CreatedAt: time.Now(),
-       }
+	recurring := true
+	if req.Recurring != nil {
+		recurring = *req.Recurring
+	}
+	carryOver := req.CarryOver
+	if carryOver == "" {
+		carryOver = budget.CarryOverNone
+	}
+	alertThresholds := req.AlertThresholds
+	if alertThresholds == nil {
+		alertThresholds = budget.DefaultAlertThresholds
+	}
+	categoryIDs, serviceErr := s.trackedCategories(c, req.CategoryIDs, userId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	currency, serviceErr := resolveCurrency(c, s.userDatabase, userId, req.Currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
 
-       if err := s.databaseService.CreateBudget(budget); err != nil {
-              appErr := errors.NewInternalError(err)
-              c.Error(appErr)
-              return nil, ServiceErrorFromAppError(appErr)
-       }
+	b := &models.Budget{
+		ID:              uuid.New(),
+		UserID:          userId,
+		Type:            req.Type,
+		Name:            req.Name,
+		StartDate:       req.StartDate,
+		EndDate:         req.EndDate,
+		Amount:          req.Amount,
+		Currency:        currency,
+		CreatedAt:       time.Now(),
+		Recurring:       recurring,
+		CarryOver:       carryOver,
+		AnchorStartDate: req.StartDate,
+		AnchorEndDate:   req.EndDate,
+		AlertThresholds: alertThresholds,
+		CategoryIDs:     categoryIDs,
+	}
 
-       return budget, nil
+	if err := s.databaseService.CreateBudget(b); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return b, nil
 }
 
+// UpdateBudget changes the budget and its current period. New dates restart the series from them.
 func (s *BudgetService) UpdateBudget(c *gin.Context, req *models.UpdateBudgetRequest, budgetId uuid.UUID, userId uuid.UUID) (*models.Budget, *ServiceError) {
-       // Fetch existing budget to verify ownership
-       _, err := s.databaseService.GetBudgetByID(budgetId, userId)
-       if err != nil {
-              appErr := errors.NewNotFoundError("budget", err)
-              c.Error(appErr)
-              return nil, ServiceErrorFromAppError(appErr)
-       }
+	// Fetch existing budget to verify ownership
+	existing, err := s.databaseService.GetBudgetByID(budgetId, userId)
+	if err != nil || existing == nil {
+		appErr := errors.NewNotFoundError("budget", err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
 
-       updates := make(map[string]any)
-       if req.Name != nil {
-              updates["name"] = *req.Name
-       }
-       if req.StartDate != nil {
-              updates["start_date"] = *req.StartDate
-       }
-       if req.EndDate != nil {
-              updates["end_date"] = *req.EndDate
-       }
-       if req.Amount != nil {
-              updates["amount"] = *req.Amount
-       }
+	updates := make(map[string]any)
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Amount != nil {
+		updates["amount"] = *req.Amount
+	}
+	if req.Recurring != nil {
+		updates["recurring"] = *req.Recurring
+	}
+	if req.CarryOver != nil {
+		updates["carry_over"] = *req.CarryOver
+	}
+	if req.AlertThresholds != nil {
+		// Updating through a map skips the column's serializer
+		encoded, err := json.Marshal(req.AlertThresholds)
+		if err != nil {
+			appErr := errors.NewBadRequestError("invalid alert thresholds", err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		updates["alert_thresholds"] = string(encoded)
+	}
+	if req.StartDate != nil || req.EndDate != nil {
+		startDate, endDate := existing.StartDate, existing.EndDate
+		if req.StartDate != nil {
+			startDate = *req.StartDate
+		}
+		if req.EndDate != nil {
+			endDate = *req.EndDate
+		}
+		if !endDate.After(startDate) {
+			appErr := errors.NewBadRequestError("end date must be after start date", nil)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		updates["start_date"] = startDate
+		updates["end_date"] = endDate
+		updates["anchor_start_date"] = startDate
+		updates["anchor_end_date"] = endDate
+		updates["period_index"] = 0
+	}
 
-       // Save updated budget
-       err = s.databaseService.UpdateBudget(budgetId, updates)
-       if err != nil {
-              appErr := errors.NewInternalError(err)
-              c.Error(appErr)
-              return nil, ServiceErrorFromAppError(appErr)
-       }
+	var categoryIDs []uuid.UUID
+	if req.CategoryIDs != nil {
+		var serviceErr *ServiceError
+		categoryIDs, serviceErr = s.trackedCategories(c, req.CategoryIDs, userId)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
+	}
 
-       // Fetch updated budget
-       updatedBudget, err := s.databaseService.GetBudgetByID(budgetId, userId)
-       if err != nil {
-              appErr := errors.NewInternalError(err)
-              c.Error(appErr)
-              return nil, ServiceErrorFromAppError(appErr)
-       }
+	// Save updated budget
+	if len(updates) > 0 {
+		if err := s.databaseService.UpdateBudget(budgetId, updates); err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+	}
+	if req.CategoryIDs != nil {
+		if err := s.databaseService.SetBudgetCategories(budgetId, categoryIDs); err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+	}
 
-       return updatedBudget, nil
+	// Fetch updated budget
+	updatedBudget, err := s.databaseService.GetBudgetByID(budgetId, userId)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return updatedBudget, nil
 }
 
-func (s *BudgetService) DeleteBudget(c *gin.Context, budgetId uuid.UUID, userId uuid.UUID) *ServiceError {
-       // Verify budget exists and belongs to user
-       _, err := s.databaseService.GetBudgetByID(budgetId, userId)
-       if err != nil {
-              appErr := errors.NewNotFoundError("budget", err)
-              c.Error(appErr)
-              return ServiceErrorFromAppError(appErr)
-       }
+// DeleteBudget removes the budget after nulling out, reassigning or refusing to touch the
+// transactions that use it, depending on the requested or configured policy
+func (s *BudgetService) DeleteBudget(c *gin.Context, req *models.DeleteBudgetRequest, budgetId uuid.UUID, userId uuid.UUID) *ServiceError {
+	budget, err := s.databaseService.GetBudgetByID(budgetId, userId)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+	if budget == nil {
+		appErr := errors.NewNotFoundError("budget", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
 
-       err = s.databaseService.DeleteBudget(budgetId)
-       if err != nil {
-              appErr := errors.NewInternalError(err)
-              c.Error(appErr)
-              return ServiceErrorFromAppError(appErr)
-       }
+	policy := req.OnDelete
+	if policy == "" {
+		policy = s.config.DeleteReferencePolicy
+	}
 
-       return nil
+	switch policy {
+	case config.DeleteReferenceBlock:
+		txns, err := s.transactionDatabase.GetTransactionsByBudget(userId, budgetId)
+		if err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return ServiceErrorFromAppError(appErr)
+		}
+		if len(txns) > 0 {
+			appErr := errors.NewConflictError("budget is still used by transactions", nil)
+			c.Error(appErr)
+			serviceErr := ServiceErrorFromAppError(appErr)
+			serviceErr.Data = txns
+			return serviceErr
+		}
+		err = s.databaseService.DeleteBudget(budgetId)
+	case config.DeleteReferenceReassign:
+		replacement, serviceErr := s.replacementBudget(c, req.ReassignTo, budget)
+		if serviceErr != nil {
+			return serviceErr
+		}
+		err = s.databaseService.DeleteBudgetAndReassign(budgetId, &replacement.ID)
+	default:
+		err = s.databaseService.DeleteBudgetAndReassign(budgetId, nil)
+	}
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	return nil
 }
 
-func (s *BudgetService) GetBudgetsByUserID(c *gin.Context, userId uuid.UUID) ([]models.Budget, *ServiceError) {
-       budgets, err := s.databaseService.GetBudgetsByUser(userId)
-       if err != nil {
-              appErr := errors.NewInternalError(err)
-              c.Error(appErr)
-              return nil, ServiceErrorFromAppError(appErr)
-       }
+func (s *BudgetService) GetBudgetsByUserID(c *gin.Context, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[models.Budget], *ServiceError) {
+	query, serviceErr := budgetPages.query(c, pageReq)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
 
-       return budgets, nil
+	budgets, err := s.databaseService.GetBudgetsByUser(userId, query)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return budgetPages.page(c, pageReq, query, budgets)
 }
 
 func (s *BudgetService) GetBudgetByID(c *gin.Context, budgetID uuid.UUID, userId uuid.UUID) (*models.Budget, *ServiceError) {
        budget, err := s.databaseService.GetBudgetByID(budgetID, userId)
-       if err != nil {
+       if err != nil || budget == nil {
               appErr := errors.NewNotFoundError("budget", err)
               c.Error(appErr)
               return nil, ServiceErrorFromAppError(appErr)
@@ -129,3 +291,228 @@ func (s *BudgetService) GetBudgetByID(c *gin.Context, budgetID uuid.UUID, userId
 
        return budget, nil
 }
+
+// replacementBudget resolves the budget transactions move to when their budget is deleted
+func (s *BudgetService) replacementBudget(c *gin.Context, reassignTo string, budget *models.Budget) (*models.Budget, *ServiceError) {
+	if reassignTo == "" {
+		appErr := errors.NewBadRequestError("reassign_to is required when on_delete is reassign", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	replacementId, err := uuid.Parse(reassignTo)
+	if err != nil || replacementId == budget.ID {
+		appErr := errors.NewBadRequestError("reassign_to must be another budget", err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	replacement, err := s.databaseService.GetBudgetByID(replacementId, budget.UserID)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+	if replacement == nil {
+		appErr := errors.NewBadRequestError("reassign_to budget not found", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return replacement, nil
+}
+
+// RollOverDue starts the next period of every recurring budget whose period ended before now,
+// catching up on any periods missed while the server was down, and returns how many periods it started
+func (s *BudgetService) RollOverDue(now time.Time) (int, error) {
+	budgets, err := s.databaseService.GetBudgetsToRollOver(now, rolloverBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	rolled := 0
+	locations := make(map[uuid.UUID]*time.Location)
+	for i := range budgets {
+		location, ok := locations[budgets[i].UserID]
+		if !ok {
+			user, err := s.userDatabase.GetUserByID(budgets[i].UserID)
+			if err != nil {
+				return rolled, err
+			}
+			location = time.UTC
+			if user != nil {
+				location = user.Location()
+			}
+			locations[budgets[i].UserID] = location
+		}
+
+		count, err := s.rollOver(&budgets[i], location, now)
+		rolled += count
+		if err != nil {
+			utils.GetLogger().Error().Err(err).Str("budget_id", budgets[i].ID.String()).Msg("Failed to roll over budget")
+		}
+	}
+
+	return rolled, nil
+}
+
+// rollOver starts the budget's periods up to now, stepping through days and months in location
+func (s *BudgetService) rollOver(b *models.Budget, location *time.Location, now time.Time) (int, error) {
+	starts, ends := budgetPeriodRecurrences(b, location)
+	converter := newCurrencyConverter(context.Background(), s.exchangeRates, b.Currency)
+	rolled := 0
+	for b.EndDate.Before(now) {
+		txns, err := s.transactionDatabase.GetTransactionsByBudgetAndDateRange(b.UserID, b.ID, b.StartDate, b.EndDate)
+		if err != nil {
+			return rolled, err
+		}
+		spent, missing, err := budgetSpending(converter, b, txns)
+		if err != nil {
+			return rolled, err
+		}
+		if len(missing) > 0 {
+			// Waiting for the rates would hold this budget back and, as due budgets are taken
+			// oldest first, every budget behind it too
+			utils.GetLogger().Warn().
+				Err(missing[0]).
+				Str("budget_id", b.ID.String()).
+				Int("unconverted", len(missing)).
+				Msg("Rolling budget over without expenses that have no exchange rate")
+		}
+
+		startDate, _ := starts.Occurrence(b.PeriodIndex + 1)
+		endDate, _ := ends.Occurrence(b.PeriodIndex + 1)
+		period := &models.BudgetPeriod{
+			ID:          uuid.New(),
+			BudgetID:    b.ID,
+			StartDate:   startDate,
+			EndDate:     endDate,
+			Amount:      b.Amount,
+			CarriedOver: carriedOver(b.CarryOver, b.Amount+b.CarriedOver-spent),
+			CreatedAt:   time.Now(),
+		}
+
+		advanced, err := s.databaseService.RollOverBudget(b, b.PeriodIndex, period)
+		if err != nil || !advanced {
+			// Not advancing means another instance got there first
+			return rolled, err
+		}
+
+		b.StartDate, b.EndDate, b.CarriedOver = period.StartDate, period.EndDate, period.CarriedOver
+		b.PeriodIndex++
+		rolled++
+	}
+
+	return rolled, nil
+}
+
+// budgetSpending totals what the expenses among txns spend from b in the converter's currency, the
+// budget's own. Expenses without an exchange rate are left out of the total and returned, so the
+// caller decides whether an incomplete total will do.
+func budgetSpending(converter *currencyConverter, b *models.Budget, txns []*models.Transaction) (models.Money, []*missingRateError, error) {
+	tracked := make(map[uuid.UUID]bool, len(b.CategoryIDs))
+	for _, categoryID := range b.CategoryIDs {
+		tracked[categoryID] = true
+	}
+
+	var spent models.Money
+	var missing []*missingRateError
+	for _, txn := range txns {
+		if txn.Type != "expense" {
+			continue
+		}
+		amount, err := converter.convert(budgetShare(txn, b.ID, tracked), txn.Currency, txn.Date)
+		if noRate, ok := err.(*missingRateError); ok {
+			missing = append(missing, noRate)
+			continue
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		spent += amount
+	}
+	return spent, missing, nil
+}
+
+// budgetShare is the part of txn that counts towards a budget: all of it when it names the budget,
+// otherwise its amount or its splits' amounts in the tracked categories, the same way category
+// reports count splits
+func budgetShare(txn *models.Transaction, budgetID uuid.UUID, tracked map[uuid.UUID]bool) models.Money {
+	if txn.BudgetID != nil && *txn.BudgetID == budgetID {
+		return txn.Amount
+	}
+	if len(txn.Splits) == 0 {
+		if txn.CategoryID != nil && tracked[*txn.CategoryID] {
+			return txn.Amount
+		}
+		return 0
+	}
+
+	var share models.Money
+	for _, split := range txn.Splits {
+		if split.CategoryID != nil && tracked[*split.CategoryID] {
+			share += split.Amount
+		}
+	}
+	return share
+}
+
+// trackedCategories checks that every category a budget should track is one of the user's expense categories
+func (s *BudgetService) trackedCategories(c *gin.Context, categoryIDs []string, userId uuid.UUID) ([]uuid.UUID, *ServiceError) {
+	tracked := make([]uuid.UUID, 0, len(categoryIDs))
+	seen := make(map[uuid.UUID]bool, len(categoryIDs))
+	for _, categoryIDStr := range categoryIDs {
+		categoryID, err := uuid.Parse(categoryIDStr)
+		if err != nil {
+			appErr := errors.NewBadRequestError("invalid category ID", err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		if seen[categoryID] {
+			continue
+		}
+		seen[categoryID] = true
+
+		category, err := s.categoryDatabase.GetCategoryByID(categoryID, userId)
+		if err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		if category == nil {
+			appErr := errors.NewBadRequestError("category not found", nil)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		if category.Type != "expense" {
+			appErr := errors.NewBadRequestError("budgets can only track expense categories", nil)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		tracked = append(tracked, categoryID)
+	}
+	return tracked, nil
+}
+
+// budgetPeriodRecurrences returns the schedules period start and end dates follow. Both are
+// computed from the first period so a budget starting on the 31st keeps ending on month ends, and
+// in the owner's timezone so a period starting at local midnight keeps doing so across DST changes.
+func budgetPeriodRecurrences(b *models.Budget, location *time.Location) (utils.Recurrence, utils.Recurrence) {
+	starts := utils.Recurrence{Frequency: b.Type.Frequency(), Interval: 1, Start: b.AnchorStartDate.In(location)}
+	ends := utils.Recurrence{Frequency: b.Type.Frequency(), Interval: 1, Start: b.AnchorEndDate.In(location)}
+	return starts, ends
+}
+
+// carriedOver is how much of what a period has left, negative when it went over, moves into the next one
+func carriedOver(policy string, remaining models.Money) models.Money {
+	var carried models.Money
+	switch policy {
+	case budget.CarryOverUnspent:
+		carried = max(remaining, 0)
+	case budget.CarryOverOverspent:
+		carried = min(remaining, 0)
+	case budget.CarryOverAll:
+		carried = remaining
+	}
+	return carried
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"github.com/AlsoShantanuBorkar/budget_max/config"
 	"github.com/AlsoShantanuBorkar/budget_max/database"
 	"github.com/AlsoShantanuBorkar/budget_max/errors"
 	"github.com/AlsoShantanuBorkar/budget_max/models"
@@ -11,17 +12,32 @@ import (
 type CategoryServiceInterface interface {
 	CreateCategory(c *gin.Context, req *models.CreateCategoryRequest, userId uuid.UUID) (*models.Category, *ServiceError)
 	UpdateCategory(c *gin.Context, req *models.UpdateCategoryRequest, categoryId uuid.UUID, userId uuid.UUID) (*models.Category, *ServiceError)
-	DeleteCategory(c *gin.Context, categoryId uuid.UUID, userId uuid.UUID) *ServiceError
-	GetCategoriesByUserID(c *gin.Context, userId uuid.UUID) ([]models.Category, *ServiceError)
+	DeleteCategory(c *gin.Context, req *models.DeleteCategoryRequest, categoryId uuid.UUID, userId uuid.UUID) *ServiceError
+	GetCategoriesByUserID(c *gin.Context, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[models.Category], *ServiceError)
 	GetCategoryByID(c *gin.Context, categoryID uuid.UUID, userId uuid.UUID) (*models.Category, *ServiceError)
 }
 
+var categoryPages = pageSpec[models.Category]{
+	keys: map[string]sortKey[models.Category]{
+		"name": {column: "name", value: func(cat models.Category) any { return cat.Name }, decode: decodeCursorValue[string]},
+		"type": {column: "type", value: func(cat models.Category) any { return cat.Type }, decode: decodeCursorValue[string]},
+	},
+	defaultSort: "name",
+	id:          func(cat models.Category) uuid.UUID { return cat.ID },
+}
+
 type CategoryService struct {
-	databaseService database.CategoryDatabaseServiceInterface
+	databaseService     database.CategoryDatabaseServiceInterface
+	transactionDatabase database.TransactionDatabaseServiceInterface
+	config              *config.AppConfig
 }
 
-func NewCategoryService(dbService database.CategoryDatabaseServiceInterface) CategoryServiceInterface {
-	return &CategoryService{databaseService: dbService}
+func NewCategoryService(dbService database.CategoryDatabaseServiceInterface, transactionDBService database.TransactionDatabaseServiceInterface, config *config.AppConfig) CategoryServiceInterface {
+	return &CategoryService{
+		databaseService:     dbService,
+		transactionDatabase: transactionDBService,
+		config:              config,
+	}
 }
 
 func (s *CategoryService) CreateCategory(c *gin.Context, req *models.CreateCategoryRequest, userId uuid.UUID) (*models.Category, *ServiceError) {
@@ -85,34 +101,74 @@ func (s *CategoryService) UpdateCategory(c *gin.Context, req *models.UpdateCateg
 	return updatedCategory, nil
 }
 
-func (s *CategoryService) DeleteCategory(c *gin.Context, categoryId uuid.UUID, userId uuid.UUID) *ServiceError {
-	// Verify category exists and belongs to user
-       _, err := s.databaseService.GetCategoryByID(categoryId, userId)
-       if err != nil {
-	       appErr := errors.NewNotFoundError("category", err)
-	       c.Error(appErr)
-	       return ServiceErrorFromAppError(appErr)
-       }
+// DeleteCategory removes the category after nulling out, reassigning or refusing to touch the
+// transactions that use it, depending on the requested or configured policy
+func (s *CategoryService) DeleteCategory(c *gin.Context, req *models.DeleteCategoryRequest, categoryId uuid.UUID, userId uuid.UUID) *ServiceError {
+	category, err := s.databaseService.GetCategoryByID(categoryId, userId)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+	if category == nil {
+		appErr := errors.NewNotFoundError("category", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
 
-       err = s.databaseService.DeleteCategory(categoryId)
-       if err != nil {
-	       appErr := errors.NewInternalError(err)
-	       c.Error(appErr)
-	       return ServiceErrorFromAppError(appErr)
-       }
+	policy := req.OnDelete
+	if policy == "" {
+		policy = s.config.DeleteReferencePolicy
+	}
+
+	switch policy {
+	case config.DeleteReferenceBlock:
+		txns, err := s.transactionDatabase.GetTransactionsByCategory(userId, categoryId)
+		if err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return ServiceErrorFromAppError(appErr)
+		}
+		if len(txns) > 0 {
+			appErr := errors.NewConflictError("category is still used by transactions", nil)
+			c.Error(appErr)
+			serviceErr := ServiceErrorFromAppError(appErr)
+			serviceErr.Data = txns
+			return serviceErr
+		}
+		err = s.databaseService.DeleteCategory(categoryId)
+	case config.DeleteReferenceReassign:
+		replacement, serviceErr := s.replacementCategory(c, req.ReassignTo, category)
+		if serviceErr != nil {
+			return serviceErr
+		}
+		err = s.databaseService.DeleteCategoryAndReassign(categoryId, &replacement.ID)
+	default:
+		err = s.databaseService.DeleteCategoryAndReassign(categoryId, nil)
+	}
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
 
 	return nil
 }
 
-func (s *CategoryService) GetCategoriesByUserID(c *gin.Context, userId uuid.UUID) ([]models.Category, *ServiceError) {
-       categories, err := s.databaseService.GetUserCategories(userId)
-       if err != nil {
-	       appErr := errors.NewInternalError(err)
-	       c.Error(appErr)
-	       return nil, ServiceErrorFromAppError(appErr)
-       }
+func (s *CategoryService) GetCategoriesByUserID(c *gin.Context, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[models.Category], *ServiceError) {
+	query, serviceErr := categoryPages.query(c, pageReq)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	categories, err := s.databaseService.GetUserCategories(userId, query)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
 
-	return categories, nil
+	return categoryPages.page(c, pageReq, query, categories)
 }
 
 func (s *CategoryService) GetCategoryByID(c *gin.Context, categoryID uuid.UUID, userId uuid.UUID) (*models.Category, *ServiceError) {
@@ -125,3 +181,39 @@ func (s *CategoryService) GetCategoryByID(c *gin.Context, categoryID uuid.UUID,
 
 	return category, nil
 }
+
+// replacementCategory resolves the category transactions move to when their category is deleted.
+// It must belong to the same user and track the same type of transaction.
+func (s *CategoryService) replacementCategory(c *gin.Context, reassignTo string, category *models.Category) (*models.Category, *ServiceError) {
+	if reassignTo == "" {
+		appErr := errors.NewBadRequestError("reassign_to is required when on_delete is reassign", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	replacementId, err := uuid.Parse(reassignTo)
+	if err != nil || replacementId == category.ID {
+		appErr := errors.NewBadRequestError("reassign_to must be another category", err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	replacement, err := s.databaseService.GetCategoryByID(replacementId, category.UserID)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+	if replacement == nil {
+		appErr := errors.NewBadRequestError("reassign_to category not found", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+	if replacement.Type != category.Type {
+		appErr := errors.NewBadRequestError("reassign_to category must have the same type", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return replacement, nil
+}
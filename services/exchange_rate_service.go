@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/database"
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/exchangerates"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// rateMaxAge is how far before a date a rate may have been published and still be used for it,
+// enough to cover weekends and holidays when no rates are published
+const rateMaxAge = 7 * 24 * time.Hour
+
+// providerRetryInterval is how long after asking the provider about a day it is not asked again,
+// so a day it has no rates for does not cost a provider call on every report
+const providerRetryInterval = time.Hour
+
+// maxProviderFetches bounds the provider calls one converter, e.g. one report, makes. Amounts on
+// further days without a stored rate fail as missing rates rather than stall the request.
+const maxProviderFetches = 5
+
+type ExchangeRateServiceInterface interface {
+	// Rate returns what one unit of from was worth in to on the given date, and false when no
+	// rate is known
+	Rate(ctx context.Context, from string, to string, on time.Time) (float64, bool, error)
+}
+
+type ExchangeRateService struct {
+	databaseService database.ExchangeRateDatabaseServiceInterface
+	provider        exchangerates.Provider
+
+	mu sync.Mutex
+	// asked holds when the provider was last asked for each day's rates
+	asked map[time.Time]time.Time
+}
+
+func NewExchangeRateService(dbService database.ExchangeRateDatabaseServiceInterface, provider exchangerates.Provider) ExchangeRateServiceInterface {
+	return &ExchangeRateService{
+		databaseService: dbService,
+		provider:        provider,
+		asked:           make(map[time.Time]time.Time),
+	}
+}
+
+// Rate looks the rate up among the stored ones first and asks the provider for the rates of that
+// day when none is stored, keeping what it returns for next time. The provider is not asked about
+// a day again within providerRetryInterval, nor beyond the limit set on ctx.
+func (s *ExchangeRateService) Rate(ctx context.Context, from string, to string, on time.Time) (float64, bool, error) {
+	if from == to {
+		return 1, true, nil
+	}
+
+	day := time.Date(on.Year(), on.Month(), on.Day(), 0, 0, 0, 0, time.UTC)
+	rate, found, err := s.databaseService.GetExchangeRate(from, to, day.Add(-rateMaxAge), day)
+	if err != nil || found || s.provider == nil || !s.claimProviderFetch(ctx, day) {
+		return rate, found, err
+	}
+
+	rates, err := s.provider.Rates(ctx, day)
+	if err != nil {
+		return 0, false, err
+	}
+	if err := s.databaseService.SaveExchangeRates(rates); err != nil {
+		return 0, false, err
+	}
+	return s.databaseService.GetExchangeRate(from, to, day.Add(-rateMaxAge), day)
+}
+
+// claimProviderFetch reports whether the provider may be asked for the day's rates, recording the
+// attempt up front so concurrent requests and failed attempts don't ask again right away
+func (s *ExchangeRateService) claimProviderFetch(ctx context.Context, day time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := s.asked[day]; ok && now.Sub(last) < providerRetryInterval {
+		return false
+	}
+	if remaining, ok := ctx.Value(providerFetchesKey{}).(*atomic.Int64); ok && remaining.Add(-1) < 0 {
+		return false
+	}
+
+	// Forget old attempts so the map stays as small as the days recently asked about
+	for askedDay, last := range s.asked {
+		if now.Sub(last) >= providerRetryInterval {
+			delete(s.asked, askedDay)
+		}
+	}
+	s.asked[day] = now
+	return true
+}
+
+type providerFetchesKey struct{}
+
+// withProviderFetchLimit lets Rate calls made with the returned context ask the provider at most limit times
+func withProviderFetchLimit(ctx context.Context, limit int) context.Context {
+	remaining := new(atomic.Int64)
+	remaining.Store(int64(limit))
+	return context.WithValue(ctx, providerFetchesKey{}, remaining)
+}
+
+type rateKey struct {
+	from string
+	day  string
+}
+
+// currencyConverter converts amounts into one currency at the rate of each amount's date, looking
+// every rate up only once
+type currencyConverter struct {
+	rates    ExchangeRateServiceInterface
+	ctx      context.Context
+	currency string
+	cache    map[rateKey]float64
+}
+
+func newCurrencyConverter(ctx context.Context, rates ExchangeRateServiceInterface, currency string) *currencyConverter {
+	return &currencyConverter{
+		rates:    rates,
+		ctx:      withProviderFetchLimit(ctx, maxProviderFetches),
+		currency: currency,
+		cache:    make(map[rateKey]float64),
+	}
+}
+
+// missingRateError reports an amount that cannot be converted because no rate is known
+type missingRateError struct {
+	from string
+	to   string
+	on   time.Time
+}
+
+func (e *missingRateError) Error() string {
+	return fmt.Sprintf("no exchange rate from %s to %s on %s", e.from, e.to, e.on.Format("2006-01-02"))
+}
+
+func (cv *currencyConverter) convert(amount models.Money, from string, on time.Time) (models.Money, error) {
+	if from == cv.currency {
+		return amount, nil
+	}
+
+	key := rateKey{from: from, day: on.Format("2006-01-02")}
+	rate, ok := cv.cache[key]
+	if !ok {
+		var found bool
+		var err error
+		rate, found, err = cv.rates.Rate(cv.ctx, from, cv.currency, on)
+		if err != nil {
+			return 0, err
+		}
+		if !found {
+			return 0, &missingRateError{from: from, to: cv.currency, on: on}
+		}
+		cv.cache[key] = rate
+	}
+	return amount.Mul(rate), nil
+}
+
+// conversionError records a failed conversion on the request, as a bad request when the rate is
+// missing so the client knows to pick another currency
+func conversionError(c *gin.Context, err error) *ServiceError {
+	appErr := errors.NewInternalError(err)
+	if missing, ok := err.(*missingRateError); ok {
+		appErr = errors.NewBadRequestError(missing.Error(), err)
+	}
+	c.Error(appErr)
+	return ServiceErrorFromAppError(appErr)
+}
+
+// resolveCurrency returns the requested currency, or the user's base currency when none was requested
+func resolveCurrency(c *gin.Context, userDatabase database.UserDatabaseServiceInterface, userId uuid.UUID, requested string) (string, *ServiceError) {
+	if requested != "" {
+		return requested, nil
+	}
+
+	user, err := userDatabase.GetUserByID(userId)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return "", ServiceErrorFromAppError(appErr)
+	}
+	if user == nil {
+		appErr := errors.NewNotFoundError("user", nil)
+		c.Error(appErr)
+		return "", ServiceErrorFromAppError(appErr)
+	}
+	return user.BaseCurrency, nil
+}
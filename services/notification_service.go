@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/database"
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/AlsoShantanuBorkar/budget_max/models/notifications"
+	"github.com/AlsoShantanuBorkar/budget_max/notifier"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// deliveryTimeout bounds how long all channels together may take to deliver one notification
+const deliveryTimeout = 30 * time.Second
+
+var notificationPages = pageSpec[models.Notification]{
+	keys: map[string]sortKey[models.Notification]{
+		"created_at": {column: "created_at", value: func(n models.Notification) any { return n.CreatedAt }, decode: decodeCursorValue[time.Time]},
+	},
+	defaultSort: "-created_at",
+	id:          func(n models.Notification) uuid.UUID { return n.ID },
+}
+
+type NotificationServiceInterface interface {
+	GetNotifications(c *gin.Context, req *models.NotificationsRequest, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[models.Notification], *ServiceError)
+	MarkNotificationRead(c *gin.Context, id uuid.UUID, userId uuid.UUID) *ServiceError
+	MarkAllNotificationsRead(c *gin.Context, userId uuid.UUID) *ServiceError
+	CheckBudgetThresholds(userId uuid.UUID, budgetId uuid.UUID)
+	CheckTransactionBudgets(txn *models.Transaction)
+}
+
+type NotificationService struct {
+	databaseService     database.NotificationDatabaseServiceInterface
+	budgetDatabase      database.BudgetDatabaseServiceInterface
+	transactionDatabase database.TransactionDatabaseServiceInterface
+	userDatabase        database.UserDatabaseServiceInterface
+	exchangeRates       ExchangeRateServiceInterface
+	channels            []notifier.Channel
+}
+
+func NewNotificationService(dbService database.NotificationDatabaseServiceInterface, budgetDBService database.BudgetDatabaseServiceInterface, transactionDBService database.TransactionDatabaseServiceInterface, userDBService database.UserDatabaseServiceInterface, exchangeRates ExchangeRateServiceInterface, channels []notifier.Channel) NotificationServiceInterface {
+	return &NotificationService{
+		databaseService:     dbService,
+		budgetDatabase:      budgetDBService,
+		transactionDatabase: transactionDBService,
+		userDatabase:        userDBService,
+		exchangeRates:       exchangeRates,
+		channels:            channels,
+	}
+}
+
+func (s *NotificationService) GetNotifications(c *gin.Context, req *models.NotificationsRequest, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[models.Notification], *ServiceError) {
+	query, serviceErr := notificationPages.query(c, pageReq)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	items, err := s.databaseService.GetNotificationsByUser(userId, req.Unread, query)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return notificationPages.page(c, pageReq, query, items)
+}
+
+func (s *NotificationService) MarkNotificationRead(c *gin.Context, id uuid.UUID, userId uuid.UUID) *ServiceError {
+	found, err := s.databaseService.MarkNotificationRead(id, userId, time.Now())
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+	if !found {
+		appErr := errors.NewNotFoundError("notification", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	return nil
+}
+
+func (s *NotificationService) MarkAllNotificationsRead(c *gin.Context, userId uuid.UUID) *ServiceError {
+	if err := s.databaseService.MarkAllNotificationsRead(userId, time.Now()); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	return nil
+}
+
+// CheckTransactionBudgets checks the alerts of every budget an expense counts towards: the one it
+// names and those tracking its category, or its splits' categories when it is split
+func (s *NotificationService) CheckTransactionBudgets(txn *models.Transaction) {
+	if txn.Type != "expense" {
+		return
+	}
+
+	budgetIDs := []uuid.UUID{}
+	if txn.BudgetID != nil {
+		budgetIDs = append(budgetIDs, *txn.BudgetID)
+	}
+	categoryIDs := []uuid.UUID{}
+	if len(txn.Splits) == 0 && txn.CategoryID != nil {
+		categoryIDs = append(categoryIDs, *txn.CategoryID)
+	}
+	for _, split := range txn.Splits {
+		if split.CategoryID != nil {
+			categoryIDs = append(categoryIDs, *split.CategoryID)
+		}
+	}
+	for _, categoryID := range categoryIDs {
+		tracking, err := s.budgetDatabase.GetBudgetIDsByCategory(txn.UserID, categoryID)
+		if err != nil {
+			utils.GetLogger().Error().Err(err).Str("transaction_id", txn.ID.String()).Msg("Failed to find budgets tracking category")
+		}
+		budgetIDs = append(budgetIDs, tracking...)
+	}
+
+	checked := make(map[uuid.UUID]bool, len(budgetIDs))
+	for _, budgetID := range budgetIDs {
+		if !checked[budgetID] {
+			checked[budgetID] = true
+			s.CheckBudgetThresholds(txn.UserID, budgetID)
+		}
+	}
+}
+
+// CheckBudgetThresholds notifies the user of every alert threshold that spending in the budget's
+// current period has reached, once per threshold and period. It is called after a transaction was
+// saved, so failures are logged rather than failing the request.
+func (s *NotificationService) CheckBudgetThresholds(userId uuid.UUID, budgetId uuid.UUID) {
+	if err := s.checkBudgetThresholds(userId, budgetId); err != nil {
+		utils.GetLogger().Error().Err(err).Str("budget_id", budgetId.String()).Msg("Failed to check budget alerts")
+	}
+}
+
+func (s *NotificationService) checkBudgetThresholds(userId uuid.UUID, budgetId uuid.UUID) error {
+	b, err := s.budgetDatabase.GetBudgetByID(budgetId, userId)
+	if err != nil || b == nil || len(b.AlertThresholds) == 0 {
+		return err
+	}
+
+	txns, err := s.transactionDatabase.GetTransactionsByBudgetAndDateRange(userId, budgetId, b.StartDate, b.EndDate)
+	if err != nil {
+		return err
+	}
+	spent, missing, err := budgetSpending(newCurrencyConverter(context.Background(), s.exchangeRates, b.Currency), b, txns)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return missing[0]
+	}
+	if spent <= 0 {
+		return nil
+	}
+
+	available := b.Amount + b.CarriedOver
+	for _, threshold := range b.AlertThresholds {
+		if spent.Cents()*100 < available.Cents()*int64(threshold) {
+			continue
+		}
+
+		periodStart := b.StartDate
+		notification := &models.Notification{
+			ID:          uuid.New(),
+			UserID:      userId,
+			Type:        notifications.TypeBudgetThreshold,
+			Title:       fmt.Sprintf("%s budget reached %d%%", b.Name, threshold),
+			Message:     fmt.Sprintf("You have spent %s of %s %s in your %s budget for %s to %s.", spent, available, b.Currency, b.Name, b.StartDate.Format("Jan 2, 2006"), b.EndDate.Format("Jan 2, 2006")),
+			BudgetID:    &b.ID,
+			Threshold:   &threshold,
+			PeriodStart: &periodStart,
+			DedupKey:    fmt.Sprintf("budget:%s:%s:%d", b.ID, b.StartDate.UTC().Format(time.RFC3339), threshold),
+			CreatedAt:   time.Now(),
+		}
+
+		created, err := s.databaseService.CreateNotification(notification)
+		if err != nil {
+			return err
+		}
+		if created {
+			s.deliver(userId, notification)
+		}
+	}
+
+	return nil
+}
+
+// deliver hands the notification to every configured channel in the background
+func (s *NotificationService) deliver(userId uuid.UUID, notification *models.Notification) {
+	if len(s.channels) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+		defer cancel()
+
+		user, err := s.userDatabase.GetUserByID(userId)
+		if err != nil || user == nil {
+			utils.GetLogger().Error().Err(err).Str("notification_id", notification.ID.String()).Msg("Failed to load notification recipient")
+			return
+		}
+
+		recipient := notifier.Recipient{Email: user.Email, Name: user.Name}
+		for _, channel := range s.channels {
+			if err := channel.Deliver(ctx, recipient, notification); err != nil {
+				utils.GetLogger().Error().Err(err).Str("channel", channel.Name()).Str("notification_id", notification.ID.String()).Msg("Failed to deliver notification")
+			}
+		}
+	}()
+}
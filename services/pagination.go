@@ -0,0 +1,148 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AlsoShantanuBorkar/budget_max/database"
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultPageLimit is the page size when the request does not set a limit
+const defaultPageLimit = 50
+
+// sortKey is a column a list can be ordered by. value reads it from a row to build the next
+// cursor and decode reads it back out of a cursor.
+type sortKey[T any] struct {
+	column string
+	value  func(T) any
+	decode func(json.RawMessage) (any, error)
+}
+
+// pageSpec describes how a list of T can be sorted and paginated
+type pageSpec[T any] struct {
+	keys        map[string]sortKey[T]
+	defaultSort string
+	id          func(T) uuid.UUID
+}
+
+// cursorPayload is what an opaque cursor encodes. The sort is part of it so a cursor cannot be
+// replayed against a list ordered differently.
+type cursorPayload struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    uuid.UUID       `json:"id"`
+}
+
+// query checks the requested sort and cursor and turns them into a database page query
+func (p pageSpec[T]) query(c *gin.Context, req *models.PageRequest) (database.PageQuery, *ServiceError) {
+	sort := p.sort(req)
+	key, ok := p.keys[strings.TrimPrefix(sort, "-")]
+	if !ok {
+		appErr := errors.NewBadRequestError(fmt.Sprintf("cannot sort by %q", sort), nil)
+		c.Error(appErr)
+		return database.PageQuery{}, ServiceErrorFromAppError(appErr)
+	}
+
+	query := database.PageQuery{
+		Column:     key.column,
+		Descending: strings.HasPrefix(sort, "-"),
+		Limit:      req.Limit,
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultPageLimit
+	}
+
+	if req.Cursor != "" {
+		after, err := decodeCursor(req.Cursor, sort, key.decode)
+		if err != nil {
+			appErr := errors.NewBadRequestError("invalid cursor", err)
+			c.Error(appErr)
+			return database.PageQuery{}, ServiceErrorFromAppError(appErr)
+		}
+		query.After = after
+	}
+
+	return query, nil
+}
+
+// page trims the extra row fetched by the query and, when there was one, points the next cursor
+// at the last row that is returned
+func (p pageSpec[T]) page(c *gin.Context, req *models.PageRequest, query database.PageQuery, items []T) (*models.Page[T], *ServiceError) {
+	page := &models.Page[T]{Items: items}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(items) <= query.Limit {
+		return page, nil
+	}
+
+	page.Items = items[:query.Limit]
+	last := page.Items[len(page.Items)-1]
+
+	sort := p.sort(req)
+	cursor, err := encodeCursor(sort, p.keys[strings.TrimPrefix(sort, "-")].value(last), p.id(last))
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+	page.NextCursor = &cursor
+
+	return page, nil
+}
+
+func (p pageSpec[T]) sort(req *models.PageRequest) string {
+	if req.Sort == "" {
+		return p.defaultSort
+	}
+	return req.Sort
+}
+
+func encodeCursor(sort string, value any, id uuid.UUID) (string, error) {
+	rawValue, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(cursorPayload{Sort: sort, Value: rawValue, ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+func decodeCursor(cursor string, sort string, decode func(json.RawMessage) (any, error)) (*database.PageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Sort != sort {
+		return nil, fmt.Errorf("cursor was issued for sort %q", payload.Sort)
+	}
+
+	value, err := decode(payload.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &database.PageCursor{Value: value, ID: payload.ID}, nil
+}
+
+// decodeCursorValue parses a cursor value back into the Go type of its column
+func decodeCursorValue[V any](raw json.RawMessage) (any, error) {
+	var value V
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,631 @@
+package services
+
+import (
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/database"
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// occurrenceDateLayout is how a single occurrence is addressed in URLs
+	occurrenceDateLayout = "2006-01-02"
+	// defaultPreviewCount is how many occurrences a preview returns when the request does not say
+	defaultPreviewCount = 10
+	// materializeBatchSize caps how many templates one scheduler tick works through
+	materializeBatchSize = 100
+)
+
+var recurringTransactionPages = pageSpec[models.RecurringTransaction]{
+	keys: map[string]sortKey[models.RecurringTransaction]{
+		"created_at": {column: "created_at", value: func(rt models.RecurringTransaction) any { return rt.CreatedAt }, decode: decodeCursorValue[time.Time]},
+		"start_date": {column: "start_date", value: func(rt models.RecurringTransaction) any { return rt.StartDate }, decode: decodeCursorValue[time.Time]},
+		"name":       {column: "name", value: func(rt models.RecurringTransaction) any { return rt.Name }, decode: decodeCursorValue[string]},
+	},
+	defaultSort: "-created_at",
+	id:          func(rt models.RecurringTransaction) uuid.UUID { return rt.ID },
+}
+
+type RecurringTransactionServiceInterface interface {
+	CreateRecurringTransaction(c *gin.Context, req *models.CreateRecurringTransactionRequest, userId uuid.UUID) (*models.RecurringTransaction, *ServiceError)
+	UpdateRecurringTransaction(c *gin.Context, req *models.UpdateRecurringTransactionRequest, id uuid.UUID, userId uuid.UUID) (*models.RecurringTransaction, *ServiceError)
+	DeleteRecurringTransaction(c *gin.Context, id uuid.UUID, userId uuid.UUID) *ServiceError
+	GetRecurringTransactionsByUserID(c *gin.Context, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[models.RecurringTransaction], *ServiceError)
+	GetRecurringTransactionByID(c *gin.Context, id uuid.UUID, userId uuid.UUID) (*models.RecurringTransaction, *ServiceError)
+	PreviewOccurrences(c *gin.Context, req *models.PreviewRequest, id uuid.UUID, userId uuid.UUID) ([]models.Occurrence, *ServiceError)
+	SetOccurrenceException(c *gin.Context, req *models.OccurrenceExceptionRequest, id uuid.UUID, occurrenceDate string, userId uuid.UUID) (*models.RecurringTransactionException, *ServiceError)
+	DeleteOccurrenceException(c *gin.Context, id uuid.UUID, occurrenceDate string, userId uuid.UUID) *ServiceError
+	MaterializeDue(now time.Time) (int, error)
+}
+
+type RecurringTransactionService struct {
+	databaseService     database.RecurringTransactionDatabaseServiceInterface
+	transactionDatabase database.TransactionDatabaseServiceInterface
+	categoryDatabase    database.CategoryDatabaseServiceInterface
+	budgetDatabase      database.BudgetDatabaseServiceInterface
+	userDatabase        database.UserDatabaseServiceInterface
+	notificationService NotificationServiceInterface
+}
+
+func NewRecurringTransactionService(dbService database.RecurringTransactionDatabaseServiceInterface, transactionDBService database.TransactionDatabaseServiceInterface, categoryDBService database.CategoryDatabaseServiceInterface, budgetDBService database.BudgetDatabaseServiceInterface, userDBService database.UserDatabaseServiceInterface, notificationService NotificationServiceInterface) RecurringTransactionServiceInterface {
+	return &RecurringTransactionService{
+		databaseService:     dbService,
+		transactionDatabase: transactionDBService,
+		categoryDatabase:    categoryDBService,
+		budgetDatabase:      budgetDBService,
+		userDatabase:        userDBService,
+		notificationService: notificationService,
+	}
+}
+
+// StartRecurringTransactionScheduler records due occurrences right away and then every interval.
+// A zero interval disables the scheduler.
+func StartRecurringTransactionScheduler(service RecurringTransactionServiceInterface, interval time.Duration) {
+	startScheduler(interval, func(now time.Time) {
+		created, err := service.MaterializeDue(now)
+		if err != nil {
+			utils.GetLogger().Error().Err(err).Msg("Failed to record recurring transactions")
+		}
+		if created > 0 {
+			utils.GetLogger().Info().Int("created", created).Msg("Recorded recurring transactions")
+		}
+	})
+}
+
+// CreateRecurringTransaction stores a new template. Occurrences between a start date in the past
+// and now are recorded on the next scheduler run.
+func (s *RecurringTransactionService) CreateRecurringTransaction(c *gin.Context, req *models.CreateRecurringTransactionRequest, userId uuid.UUID) (*models.RecurringTransaction, *ServiceError) {
+	startDate, err := time.Parse(time.RFC3339, req.StartDate)
+	if err != nil {
+		appErr := errors.NewBadRequestError("invalid start date format", err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	var endDate *time.Time
+	if req.EndDate != nil {
+		parsedEndDate, err := time.Parse(time.RFC3339, *req.EndDate)
+		if err != nil {
+			appErr := errors.NewBadRequestError("invalid end date format", err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		endDate = &parsedEndDate
+	}
+
+	var categoryID *uuid.UUID
+	if req.CategoryID != "" {
+		parsedCategoryID, err := uuid.Parse(req.CategoryID)
+		if err != nil {
+			appErr := errors.NewBadRequestError("invalid category ID", err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		categoryID = &parsedCategoryID
+	}
+
+	var budgetID *uuid.UUID
+	if req.BudgetID != "" {
+		parsedBudgetID, err := uuid.Parse(req.BudgetID)
+		if err != nil {
+			appErr := errors.NewBadRequestError("invalid budget ID", err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		budgetID = &parsedBudgetID
+	}
+
+	if serviceErr := checkTransactionReferences(c, s.categoryDatabase, s.budgetDatabase, userId, categoryID, budgetID); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	currency, serviceErr := resolveCurrency(c, s.userDatabase, userId, req.Currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	interval := req.Interval
+	if interval == 0 {
+		interval = 1
+	}
+
+	now := time.Now()
+	rt := &models.RecurringTransaction{
+		ID:         uuid.New(),
+		UserID:     userId,
+		Name:       req.Name,
+		Amount:     req.Amount,
+		Currency:   currency,
+		Type:       req.Type,
+		Note:       req.Note,
+		CategoryID: categoryID,
+		BudgetID:   budgetID,
+		Frequency:  req.Frequency,
+		Interval:   interval,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Count:      req.Count,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if serviceErr := checkSchedule(c, rt); serviceErr != nil {
+		return nil, serviceErr
+	}
+	if first, ok := recurrenceOf(rt).Occurrence(0); ok {
+		rt.NextOccurrenceAt = &first
+	}
+
+	if err := s.databaseService.CreateRecurringTransaction(rt); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return rt, nil
+}
+
+// UpdateRecurringTransaction changes the template. Changing the schedule drops the exceptions made
+// for the old one and continues from the first new occurrence after now instead of backfilling.
+func (s *RecurringTransactionService) UpdateRecurringTransaction(c *gin.Context, req *models.UpdateRecurringTransactionRequest, id uuid.UUID, userId uuid.UUID) (*models.RecurringTransaction, *ServiceError) {
+	rt, serviceErr := s.GetRecurringTransactionByID(c, id, userId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	updates := make(map[string]any)
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Amount != nil {
+		updates["amount"] = *req.Amount
+	}
+	if req.Currency != nil {
+		updates["currency"] = *req.Currency
+	}
+	if req.Type != nil {
+		updates["type"] = *req.Type
+	}
+	if req.Note != nil {
+		updates["note"] = *req.Note
+	}
+	if req.CategoryID != nil {
+		categoryID, err := uuid.Parse(*req.CategoryID)
+		if err != nil {
+			appErr := errors.NewBadRequestError("invalid category ID format", err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		if serviceErr := checkTransactionReferences(c, s.categoryDatabase, s.budgetDatabase, userId, &categoryID, nil); serviceErr != nil {
+			return nil, serviceErr
+		}
+		updates["category_id"] = categoryID
+	}
+	if req.BudgetID != nil {
+		budgetID, err := uuid.Parse(*req.BudgetID)
+		if err != nil {
+			appErr := errors.NewBadRequestError("invalid budget ID format", err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		if serviceErr := checkTransactionReferences(c, s.categoryDatabase, s.budgetDatabase, userId, nil, &budgetID); serviceErr != nil {
+			return nil, serviceErr
+		}
+		updates["budget_id"] = budgetID
+	}
+
+	scheduleChanged := false
+	if req.Frequency != nil {
+		rt.Frequency = *req.Frequency
+		scheduleChanged = true
+	}
+	if req.Interval != nil {
+		rt.Interval = *req.Interval
+		scheduleChanged = true
+	}
+	if req.StartDate != nil {
+		startDate, err := time.Parse(time.RFC3339, *req.StartDate)
+		if err != nil {
+			appErr := errors.NewBadRequestError("invalid start date format", err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		rt.StartDate = startDate
+		scheduleChanged = true
+	}
+	if req.EndDate != nil {
+		endDate, err := time.Parse(time.RFC3339, *req.EndDate)
+		if err != nil {
+			appErr := errors.NewBadRequestError("invalid end date format", err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		rt.EndDate = &endDate
+		scheduleChanged = true
+	}
+	if req.Count != nil {
+		rt.Count = req.Count
+		scheduleChanged = true
+	}
+
+	if scheduleChanged {
+		if serviceErr := checkSchedule(c, rt); serviceErr != nil {
+			return nil, serviceErr
+		}
+
+		recurrence := recurrenceOf(rt)
+		var nextOccurrenceAt *time.Time
+		// Once the series is over the index points past its end, not back at its first occurrence
+		index, ok := recurrence.IndexAfter(time.Now())
+		if ok {
+			next, _ := recurrence.Occurrence(index)
+			nextOccurrenceAt = &next
+		}
+
+		updates["frequency"] = rt.Frequency
+		updates["repeat_interval"] = rt.Interval
+		updates["start_date"] = rt.StartDate
+		updates["end_date"] = rt.EndDate
+		updates["count"] = rt.Count
+		updates["occurrence_index"] = index
+		updates["next_occurrence_at"] = nextOccurrenceAt
+	}
+
+	if len(updates) > 0 {
+		updates["updated_at"] = time.Now()
+		if err := s.databaseService.UpdateRecurringTransaction(id, updates); err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+	}
+
+	if scheduleChanged {
+		if err := s.databaseService.DeleteExceptions(id); err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+	}
+
+	return s.GetRecurringTransactionByID(c, id, userId)
+}
+
+// DeleteRecurringTransaction stops the series. Transactions it already recorded are kept.
+func (s *RecurringTransactionService) DeleteRecurringTransaction(c *gin.Context, id uuid.UUID, userId uuid.UUID) *ServiceError {
+	if _, serviceErr := s.GetRecurringTransactionByID(c, id, userId); serviceErr != nil {
+		return serviceErr
+	}
+
+	if err := s.databaseService.DeleteRecurringTransaction(id); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	return nil
+}
+
+func (s *RecurringTransactionService) GetRecurringTransactionsByUserID(c *gin.Context, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[models.RecurringTransaction], *ServiceError) {
+	query, serviceErr := recurringTransactionPages.query(c, pageReq)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	rts, err := s.databaseService.GetRecurringTransactionsByUser(userId, query)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return recurringTransactionPages.page(c, pageReq, query, rts)
+}
+
+func (s *RecurringTransactionService) GetRecurringTransactionByID(c *gin.Context, id uuid.UUID, userId uuid.UUID) (*models.RecurringTransaction, *ServiceError) {
+	rt, err := s.databaseService.GetRecurringTransactionByID(id, userId)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+	if rt == nil {
+		appErr := errors.NewNotFoundError("recurring transaction", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return rt, nil
+}
+
+// PreviewOccurrences lists the next occurrences that have not been recorded yet, with skips and
+// edits applied
+func (s *RecurringTransactionService) PreviewOccurrences(c *gin.Context, req *models.PreviewRequest, id uuid.UUID, userId uuid.UUID) ([]models.Occurrence, *ServiceError) {
+	rt, serviceErr := s.GetRecurringTransactionByID(c, id, userId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	exceptions, err := s.exceptionsByOccurrence(rt.ID)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	count := req.Count
+	if count == 0 {
+		count = defaultPreviewCount
+	}
+
+	recurrence := recurrenceOf(rt)
+	occurrences := []models.Occurrence{}
+	for index := rt.OccurrenceIndex; len(occurrences) < count; index++ {
+		occurrenceDate, ok := recurrence.Occurrence(index)
+		if !ok {
+			break
+		}
+		occurrences = append(occurrences, applyException(rt, occurrenceDate, exceptions[occurrenceKey(occurrenceDate)]))
+	}
+
+	return occurrences, nil
+}
+
+// SetOccurrenceException skips or edits the occurrence scheduled on occurrenceDate (YYYY-MM-DD)
+func (s *RecurringTransactionService) SetOccurrenceException(c *gin.Context, req *models.OccurrenceExceptionRequest, id uuid.UUID, occurrenceDate string, userId uuid.UUID) (*models.RecurringTransactionException, *ServiceError) {
+	rt, serviceErr := s.GetRecurringTransactionByID(c, id, userId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	occurrence, serviceErr := s.findPendingOccurrence(c, rt, occurrenceDate)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	exception := &models.RecurringTransactionException{
+		ID:                     uuid.New(),
+		RecurringTransactionID: rt.ID,
+		OccurrenceDate:         occurrence,
+		Skip:                   req.Skip,
+		Name:                   req.Name,
+		Amount:                 req.Amount,
+		Note:                   req.Note,
+		CreatedAt:              time.Now(),
+	}
+	if req.Date != nil {
+		date, err := time.Parse(time.RFC3339, *req.Date)
+		if err != nil {
+			appErr := errors.NewBadRequestError("invalid date format", err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		exception.Date = &date
+	}
+
+	if err := s.databaseService.UpsertException(exception); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return exception, nil
+}
+
+// DeleteOccurrenceException restores the occurrence on occurrenceDate (YYYY-MM-DD) to the template's values
+func (s *RecurringTransactionService) DeleteOccurrenceException(c *gin.Context, id uuid.UUID, occurrenceDate string, userId uuid.UUID) *ServiceError {
+	rt, serviceErr := s.GetRecurringTransactionByID(c, id, userId)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	occurrence, serviceErr := s.findPendingOccurrence(c, rt, occurrenceDate)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	deleted, err := s.databaseService.DeleteException(rt.ID, occurrence)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+	if !deleted {
+		appErr := errors.NewNotFoundError("occurrence exception", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	return nil
+}
+
+// MaterializeDue records every occurrence due at now as a Transaction and returns how many it created.
+// Transaction IDs are derived from the template and occurrence, so an occurrence recorded by an
+// earlier run that crashed before saving its progress, or by another instance, is not recorded twice.
+func (s *RecurringTransactionService) MaterializeDue(now time.Time) (int, error) {
+	rts, err := s.databaseService.GetDueRecurringTransactions(now, materializeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for i := range rts {
+		txns, err := s.materialize(&rts[i], now)
+		created += len(txns)
+		if err != nil {
+			utils.GetLogger().Error().Err(err).Str("recurring_transaction_id", rts[i].ID.String()).Msg("Failed to record recurring transaction")
+		}
+		for _, txn := range txns {
+			s.notificationService.CheckTransactionBudgets(txn)
+		}
+	}
+
+	return created, nil
+}
+
+// materialize records the recurring transaction's occurrences due by now and returns the
+// transactions it created, including those created before an error stopped it
+func (s *RecurringTransactionService) materialize(rt *models.RecurringTransaction, now time.Time) ([]*models.Transaction, error) {
+	exceptions, err := s.exceptionsByOccurrence(rt.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	recurrence := recurrenceOf(rt)
+	var created []*models.Transaction
+	for rt.NextOccurrenceAt != nil && !rt.NextOccurrenceAt.After(now) {
+		occurrence := applyException(rt, *rt.NextOccurrenceAt, exceptions[occurrenceKey(*rt.NextOccurrenceAt)])
+		if !occurrence.Skipped {
+			recurringTransactionID := rt.ID
+			txn := &models.Transaction{
+				ID:                     uuid.NewSHA1(rt.ID, []byte(occurrenceKey(occurrence.OccurrenceDate))),
+				UserID:                 rt.UserID,
+				Amount:                 occurrence.Amount,
+				Currency:               rt.Currency,
+				Type:                   rt.Type,
+				Name:                   occurrence.Name,
+				Note:                   occurrence.Note,
+				Date:                   occurrence.Date,
+				CategoryID:             rt.CategoryID,
+				BudgetID:               rt.BudgetID,
+				CreatedAt:              time.Now(),
+				RecurringTransactionID: &recurringTransactionID,
+			}
+			err := s.transactionDatabase.CreateTransaction(txn)
+			if err != nil && !database.IsUniqueViolation(err) {
+				return created, err
+			}
+			if err == nil {
+				created = append(created, txn)
+			}
+		}
+
+		rt.OccurrenceIndex++
+		rt.NextOccurrenceAt = nil
+		if next, ok := recurrence.Occurrence(rt.OccurrenceIndex); ok {
+			rt.NextOccurrenceAt = &next
+		}
+
+		// Progress is saved after every occurrence so a failure part way does not replay the rest
+		if err := s.databaseService.UpdateRecurringTransaction(rt.ID, map[string]any{
+			"occurrence_index":   rt.OccurrenceIndex,
+			"next_occurrence_at": rt.NextOccurrenceAt,
+		}); err != nil {
+			return created, err
+		}
+	}
+
+	return created, nil
+}
+
+func (s *RecurringTransactionService) exceptionsByOccurrence(recurringTransactionID uuid.UUID) (map[string]*models.RecurringTransactionException, error) {
+	exceptions, err := s.databaseService.GetExceptions(recurringTransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	byOccurrence := make(map[string]*models.RecurringTransactionException, len(exceptions))
+	for i := range exceptions {
+		byOccurrence[occurrenceKey(exceptions[i].OccurrenceDate)] = &exceptions[i]
+	}
+	return byOccurrence, nil
+}
+
+// findPendingOccurrence resolves a YYYY-MM-DD date in the user's timezone to the scheduled
+// occurrence on that day. Occurrences that were already recorded can only be changed through their
+// transaction.
+func (s *RecurringTransactionService) findPendingOccurrence(c *gin.Context, rt *models.RecurringTransaction, occurrenceDate string) (time.Time, *ServiceError) {
+	user, err := s.userDatabase.GetUserByID(rt.UserID)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return time.Time{}, ServiceErrorFromAppError(appErr)
+	}
+	location := time.UTC
+	if user != nil {
+		location = user.Location()
+	}
+
+	day, err := time.ParseInLocation(occurrenceDateLayout, occurrenceDate, location)
+	if err != nil {
+		appErr := errors.NewBadRequestError("occurrence date must be YYYY-MM-DD", err)
+		c.Error(appErr)
+		return time.Time{}, ServiceErrorFromAppError(appErr)
+	}
+
+	// Occurrences are about a day apart at most, so the first one from the day's midnight on is
+	// the one that falls on it, if any
+	recurrence := recurrenceOf(rt)
+	index, ok := recurrence.IndexAfter(day.Add(-time.Nanosecond))
+	if occurrence, _ := recurrence.Occurrence(index); ok && occurrence.In(location).Format(occurrenceDateLayout) == occurrenceDate {
+		if index < rt.OccurrenceIndex {
+			appErr := errors.NewConflictError("occurrence has already been recorded, edit its transaction instead", nil)
+			c.Error(appErr)
+			return time.Time{}, ServiceErrorFromAppError(appErr)
+		}
+		return occurrence, nil
+	}
+
+	appErr := errors.NewNotFoundError("occurrence", nil)
+	c.Error(appErr)
+	return time.Time{}, ServiceErrorFromAppError(appErr)
+}
+
+// checkSchedule rejects schedules that end before they start
+func checkSchedule(c *gin.Context, rt *models.RecurringTransaction) *ServiceError {
+	if rt.EndDate != nil && rt.EndDate.Before(rt.StartDate) {
+		appErr := errors.NewBadRequestError("end date must not be before start date", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+	return nil
+}
+
+func recurrenceOf(rt *models.RecurringTransaction) utils.Recurrence {
+	return utils.Recurrence{
+		Frequency: rt.Frequency,
+		Interval:  rt.Interval,
+		Start:     rt.StartDate,
+		Until:     rt.EndDate,
+		Count:     rt.Count,
+	}
+}
+
+// applyException returns what gets recorded for the occurrence once its exception, if any, is applied
+func applyException(rt *models.RecurringTransaction, occurrenceDate time.Time, exception *models.RecurringTransactionException) models.Occurrence {
+	occurrence := models.Occurrence{
+		OccurrenceDate: occurrenceDate,
+		Date:           occurrenceDate,
+		Name:           rt.Name,
+		Amount:         rt.Amount,
+		Note:           rt.Note,
+	}
+	if exception == nil {
+		return occurrence
+	}
+
+	occurrence.Skipped = exception.Skip
+	occurrence.Modified = exception.Name != nil || exception.Amount != nil || exception.Note != nil || exception.Date != nil
+	if exception.Name != nil {
+		occurrence.Name = *exception.Name
+	}
+	if exception.Amount != nil {
+		occurrence.Amount = *exception.Amount
+	}
+	if exception.Note != nil {
+		occurrence.Note = *exception.Note
+	}
+	if exception.Date != nil {
+		occurrence.Date = *exception.Date
+	}
+	return occurrence
+}
+
+// occurrenceKey identifies an occurrence independently of the time zone it was loaded in
+func occurrenceKey(occurrence time.Time) string {
+	return occurrence.UTC().Format(time.RFC3339Nano)
+}
@@ -1,158 +1,263 @@
 package services
 
 import (
+	"math"
 	"sort"
 	"time"
 
 	"github.com/AlsoShantanuBorkar/budget_max/database"
 	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
 	"github.com/AlsoShantanuBorkar/budget_max/models/reports"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type ReportsServiceInterface interface {
-	GetBudgetSummary(c *gin.Context, budgetID uuid.UUID, userId uuid.UUID) (*reports.BudgetSummary, *ServiceError)
-	GetWeeklySummary(c *gin.Context, userId uuid.UUID, startDate time.Time, endDate time.Time) (*reports.WeeklySummary, *ServiceError)
-	GetMonthlySummary(c *gin.Context, userId uuid.UUID, month time.Time) (*reports.MonthlySummary, *ServiceError)
-	GetYearlySummary(c *gin.Context, userId uuid.UUID, year time.Time) (*reports.YearlySummary, *ServiceError)
-	GetCategorySummary(c *gin.Context, userId uuid.UUID, categoryID uuid.UUID) (*reports.CategorySummary, *ServiceError)
-	GetCustomDateRangeSummary(c *gin.Context, userId uuid.UUID, startDate time.Time, endDate time.Time) (*reports.CustomDateRangeSummary, *ServiceError)
-	GetDailyAverageSummary(c *gin.Context, userId uuid.UUID, startDate time.Time, endDate time.Time) (*reports.DailyAverageSummary, *ServiceError)
-	GetTopCategories(c *gin.Context, userId uuid.UUID, limit int, transactionType string) ([]*reports.TopCategory, *ServiceError)
-	GetAllCategoriesSummary(c *gin.Context, userId uuid.UUID) ([]*reports.CategorySummary, *ServiceError)
+	GetBudgetSummary(c *gin.Context, budgetID uuid.UUID, userId uuid.UUID, currency string) (*reports.BudgetSummary, *ServiceError)
+	GetWeeklySummary(c *gin.Context, userId uuid.UUID, startDate time.Time, endDate time.Time, currency string) (*reports.WeeklySummary, *ServiceError)
+	GetMonthlySummary(c *gin.Context, userId uuid.UUID, month time.Time, currency string) (*reports.MonthlySummary, *ServiceError)
+	GetYearlySummary(c *gin.Context, userId uuid.UUID, year time.Time, currency string) (*reports.YearlySummary, *ServiceError)
+	GetCategorySummary(c *gin.Context, userId uuid.UUID, categoryID uuid.UUID, currency string) (*reports.CategorySummary, *ServiceError)
+	GetCustomDateRangeSummary(c *gin.Context, userId uuid.UUID, startDate time.Time, endDate time.Time, currency string) (*reports.CustomDateRangeSummary, *ServiceError)
+	GetDailyAverageSummary(c *gin.Context, userId uuid.UUID, startDate time.Time, endDate time.Time, currency string) (*reports.DailyAverageSummary, *ServiceError)
+	GetTopCategories(c *gin.Context, userId uuid.UUID, limit int, transactionType string, currency string) ([]*reports.TopCategory, *ServiceError)
+	GetAllCategoriesSummary(c *gin.Context, userId uuid.UUID, currency string) ([]*reports.CategorySummary, *ServiceError)
+	GetAllTagsSummary(c *gin.Context, userId uuid.UUID, currency string) ([]*reports.TagSummary, *ServiceError)
+	GetTimeSeries(c *gin.Context, userId uuid.UUID, interval string, startDate time.Time, endDate time.Time, groupBy string, timezone string, currency string) (*reports.TimeSeries, *ServiceError)
 }
 
+// maxTimeSeriesBuckets keeps a time series to what a chart can show, e.g. almost three years of days
+const maxTimeSeriesBuckets = 1000
+
 type ReportsService struct {
-	transactionDatabaseService database.TransactionDatabaseServiceInterface
-	categoryDatabaseService    database.CategoryDatabaseServiceInterface
-	budgetDatabaseService      database.BudgetDatabaseServiceInterface
+	reportDatabaseService   database.ReportDatabaseServiceInterface
+	categoryDatabaseService database.CategoryDatabaseServiceInterface
+	budgetDatabaseService   database.BudgetDatabaseServiceInterface
+	tagDatabaseService      database.TagDatabaseServiceInterface
+	userDatabaseService     database.UserDatabaseServiceInterface
+	exchangeRates           ExchangeRateServiceInterface
 }
 
-func NewReportsService(txnDBService database.TransactionDatabaseServiceInterface, catDBService database.CategoryDatabaseServiceInterface, budgetDBService database.BudgetDatabaseServiceInterface) ReportsServiceInterface {
+func NewReportsService(reportDBService database.ReportDatabaseServiceInterface, catDBService database.CategoryDatabaseServiceInterface, budgetDBService database.BudgetDatabaseServiceInterface, tagDBService database.TagDatabaseServiceInterface, userDBService database.UserDatabaseServiceInterface, exchangeRates ExchangeRateServiceInterface) ReportsServiceInterface {
 	return &ReportsService{
-		transactionDatabaseService: txnDBService,
-		categoryDatabaseService:    catDBService,
-		budgetDatabaseService:      budgetDBService,
+		reportDatabaseService:   reportDBService,
+		categoryDatabaseService: catDBService,
+		budgetDatabaseService:   budgetDBService,
+		tagDatabaseService:      tagDBService,
+		userDatabaseService:     userDBService,
+		exchangeRates:           exchangeRates,
 	}
 }
 
-func (s *ReportsService) GetBudgetSummary(c *gin.Context, budgetID uuid.UUID, userId uuid.UUID) (*reports.BudgetSummary, *ServiceError) {
+func (s *ReportsService) GetBudgetSummary(c *gin.Context, budgetID uuid.UUID, userId uuid.UUID, currency string) (*reports.BudgetSummary, *ServiceError) {
 	budget, err := s.budgetDatabaseService.GetBudgetByID(budgetID, userId)
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, ServiceErrorFromAppError(appErr)
        }
-	txns, err := s.transactionDatabaseService.GetTransactionsByBudget(userId, budgetID)
-       if err != nil {
-	       appErr := errors.NewInternalError(err, )
+       if budget == nil {
+	       appErr := errors.NewNotFoundError("budget", nil, )
 	       c.Error(appErr)
 	       return nil, ServiceErrorFromAppError(appErr)
        }
-	totalExpenses := 0.0
-	totalIncome := 0.0
-	for _, txn := range txns {
-		if txn.Type == "expense" {
-			totalExpenses += txn.Amount
+	// Budgets report in their own currency unless another one is requested
+	if currency == "" {
+		currency = budget.Currency
+	}
+	converter := newCurrencyConverter(c.Request.Context(), s.exchangeRates, currency)
+
+	budgetPeriods, err := s.budgetDatabaseService.GetBudgetPeriods(budgetID)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	// Expenses in tracked categories count from the first period on, on top of the transactions naming the budget
+	var trackedFrom, trackedTo time.Time
+	if len(budgetPeriods) > 0 {
+		trackedFrom, trackedTo = budgetPeriods[len(budgetPeriods)-1].StartDate, budgetPeriods[0].EndDate
+	}
+	totals, err := s.reportDatabaseService.SumByBudgetPeriod(userId, budgetID, currency, trackedFrom, trackedTo)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	// Budget amounts are converted at the rate on the day their period starts
+	budgetAmount, err := converter.convert(budget.Amount, budget.Currency, budget.StartDate)
+	if err != nil {
+		return nil, conversionError(c, err)
+	}
+	periods := make([]reports.BudgetPeriodSummary, len(budgetPeriods))
+	periodIndex := make(map[uuid.UUID]int, len(budgetPeriods))
+	for i, period := range budgetPeriods {
+		periodIndex[period.ID] = i
+		amount, err := converter.convert(period.Amount, budget.Currency, period.StartDate)
+		if err != nil {
+			return nil, conversionError(c, err)
+		}
+		carriedOver, err := converter.convert(period.CarriedOver, budget.Currency, period.StartDate)
+		if err != nil {
+			return nil, conversionError(c, err)
+		}
+		periods[i] = reports.BudgetPeriodSummary{
+			StartDate:   period.StartDate,
+			EndDate:     period.EndDate,
+			Amount:      amount,
+			CarriedOver: carriedOver,
+			Available:   amount + carriedOver,
+		}
+	}
+
+	var totalExpenses, totalIncome models.Money
+	for _, total := range totals {
+		converted, err := convertTotal(converter, total)
+		if err != nil {
+			return nil, conversionError(c, err)
+		}
+		if total.Type == "expense" {
+			totalExpenses += converted
 		} else {
-			totalIncome += txn.Amount
+			totalIncome += converted
+		}
+		if total.GroupID == nil {
+			continue
+		}
+		if i, ok := periodIndex[*total.GroupID]; ok {
+			if total.Type == "expense" {
+				periods[i].TotalExpenses += converted
+			} else {
+				periods[i].TotalIncome += converted
+			}
 		}
 	}
 	netBalance := totalIncome - totalExpenses
+	for i := range periods {
+		periods[i].Remaining = periods[i].Available - periods[i].TotalExpenses
+	}
+
 	return &reports.BudgetSummary{
 		BudgetID:      budgetID,
 		BudgetName:    budget.Name,
-		BudgetAmount:  budget.Amount,
+		BudgetAmount:  budgetAmount,
+		Currency:      currency,
 		TotalExpenses: totalExpenses,
 		TotalIncome:   totalIncome,
 		NetBalance:    netBalance,
+		Periods:       periods,
 	}, nil
 }
 
-func (s *ReportsService) GetWeeklySummary(c *gin.Context, userId uuid.UUID, startDate time.Time, endDate time.Time) (*reports.WeeklySummary, *ServiceError) {
-	txns, err := s.transactionDatabaseService.GetTransactionsByDateRange(userId, startDate, endDate)
+func (s *ReportsService) GetWeeklySummary(c *gin.Context, userId uuid.UUID, startDate time.Time, endDate time.Time, currency string) (*reports.WeeklySummary, *ServiceError) {
+	converter, serviceErr := s.reportConverter(c, userId, currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	location, _, serviceErr := s.reportCalendar(c, userId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// The end date counts in full
+	totals, err := s.reportDatabaseService.SumByType(userId, converter.currency, localDate(startDate, location), localDate(endDate, location).AddDate(0, 0, 1))
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, ServiceErrorFromAppError(appErr)
        }
-	totalExpenses := 0.0
-	totalIncome := 0.0
-	for _, txn := range txns {
-		if txn.Type == "expense" {
-			totalExpenses += txn.Amount
-		} else {
-			totalIncome += txn.Amount
-		}
+	totalExpenses, totalIncome, err := sumTotals(converter, totals)
+	if err != nil {
+		return nil, conversionError(c, err)
 	}
 	netBalance := totalIncome - totalExpenses
 	return &reports.WeeklySummary{
 		StartDate:     startDate.Format("2006-01-02"),
 		EndDate:       endDate.Format("2006-01-02"),
+		Currency:      converter.currency,
 		TotalExpenses: totalExpenses,
 		TotalIncome:   totalIncome,
 		NetBalance:    netBalance,
 	}, nil
 }
 
-func (s *ReportsService) GetMonthlySummary(c *gin.Context, userId uuid.UUID, month time.Time) (*reports.MonthlySummary, *ServiceError) {
-	startOfMonth := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
-	endOfMonth := startOfMonth.AddDate(0, 1, 0).Add(-time.Second)
+func (s *ReportsService) GetMonthlySummary(c *gin.Context, userId uuid.UUID, month time.Time, currency string) (*reports.MonthlySummary, *ServiceError) {
+	converter, serviceErr := s.reportConverter(c, userId, currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	location, _, serviceErr := s.reportCalendar(c, userId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
 
-	txns, err := s.transactionDatabaseService.GetTransactionsByDateRange(userId, startOfMonth, endOfMonth)
+	startOfMonth := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, location)
+	startOfNextMonth := startOfMonth.AddDate(0, 1, 0)
+
+	totals, err := s.reportDatabaseService.SumByType(userId, converter.currency, startOfMonth, startOfNextMonth)
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, ServiceErrorFromAppError(appErr)
        }
-	totalExpenses := 0.0
-	totalIncome := 0.0
-	for _, txn := range txns {
-		if txn.Type == "expense" {
-			totalExpenses += txn.Amount
-		} else {
-			totalIncome += txn.Amount
-		}
+	totalExpenses, totalIncome, err := sumTotals(converter, totals)
+	if err != nil {
+		return nil, conversionError(c, err)
 	}
 	netBalance := totalIncome - totalExpenses
 	return &reports.MonthlySummary{
 		Month:         month.Format("January 2006"),
+		Currency:      converter.currency,
 		TotalExpenses: totalExpenses,
 		TotalIncome:   totalIncome,
 		NetBalance:    netBalance,
 	}, nil
 }
 
-func (s *ReportsService) GetYearlySummary(c *gin.Context, userId uuid.UUID, year time.Time) (*reports.YearlySummary, *ServiceError) {
-	startOfYear := time.Date(year.Year(), 1, 1, 0, 0, 0, 0, year.Location())
-	endOfYear := time.Date(year.Year(), 12, 31, 23, 59, 59, 999999999, year.Location())
+func (s *ReportsService) GetYearlySummary(c *gin.Context, userId uuid.UUID, year time.Time, currency string) (*reports.YearlySummary, *ServiceError) {
+	converter, serviceErr := s.reportConverter(c, userId, currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	location, _, serviceErr := s.reportCalendar(c, userId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
 
-	txns, err := s.transactionDatabaseService.GetTransactionsByDateRange(userId, startOfYear, endOfYear)
+	startOfYear := time.Date(year.Year(), 1, 1, 0, 0, 0, 0, location)
+	startOfNextYear := startOfYear.AddDate(1, 0, 0)
+
+	totals, err := s.reportDatabaseService.SumByType(userId, converter.currency, startOfYear, startOfNextYear)
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, ServiceErrorFromAppError(appErr)
        }
-	totalExpenses := 0.0
-	totalIncome := 0.0
-	for _, txn := range txns {
-		if txn.Type == "expense" {
-			totalExpenses += txn.Amount
-		} else {
-			totalIncome += txn.Amount
-		}
+	totalExpenses, totalIncome, err := sumTotals(converter, totals)
+	if err != nil {
+		return nil, conversionError(c, err)
 	}
 	netBalance := totalIncome - totalExpenses
 	return &reports.YearlySummary{
 		Year:          year.Format("2006"),
+		Currency:      converter.currency,
 		TotalExpenses: totalExpenses,
 		TotalIncome:   totalIncome,
 		NetBalance:    netBalance,
 	}, nil
 }
 
-func (s *ReportsService) GetCategorySummary(c *gin.Context, userId uuid.UUID, categoryID uuid.UUID) (*reports.CategorySummary, *ServiceError) {
+func (s *ReportsService) GetCategorySummary(c *gin.Context, userId uuid.UUID, categoryID uuid.UUID, currency string) (*reports.CategorySummary, *ServiceError) {
+	converter, serviceErr := s.reportConverter(c, userId, currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
 	category, err := s.categoryDatabaseService.GetCategoryByID(categoryID, userId)
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
@@ -165,123 +270,148 @@ func (s *ReportsService) GetCategorySummary(c *gin.Context, userId uuid.UUID, ca
 	       return nil, ServiceErrorFromAppError(appErr)
        }
 
-	txns, err := s.transactionDatabaseService.GetTransactionsByCategory(userId, categoryID)
+	// Split transactions only count the part of their amount assigned to this category
+	totals, err := s.reportDatabaseService.SumByCategory(userId, converter.currency, &categoryID, "")
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, ServiceErrorFromAppError(appErr)
        }
-	totalExpenses := 0.0
-	totalIncome := 0.0
-	for _, txn := range txns {
-		if txn.Type == "expense" {
-			totalExpenses += txn.Amount
-		} else {
-			totalIncome += txn.Amount
-		}
+	totalExpenses, totalIncome, err := sumTotals(converter, totals)
+	if err != nil {
+		return nil, conversionError(c, err)
 	}
 	netBalance := totalIncome - totalExpenses
 	return &reports.CategorySummary{
 		CategoryID:    categoryID,
 		CategoryName:  category.Name,
+		Currency:      converter.currency,
 		TotalExpenses: totalExpenses,
 		TotalIncome:   totalIncome,
 		NetBalance:    netBalance,
 	}, nil
 }
 
-func (s *ReportsService) GetCustomDateRangeSummary(c *gin.Context, userId uuid.UUID, startDate time.Time, endDate time.Time) (*reports.CustomDateRangeSummary, *ServiceError) {
-	txns, err := s.transactionDatabaseService.GetTransactionsByDateRange(userId, startDate, endDate)
+func (s *ReportsService) GetCustomDateRangeSummary(c *gin.Context, userId uuid.UUID, startDate time.Time, endDate time.Time, currency string) (*reports.CustomDateRangeSummary, *ServiceError) {
+	converter, serviceErr := s.reportConverter(c, userId, currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	location, _, serviceErr := s.reportCalendar(c, userId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// The end date counts in full
+	totals, err := s.reportDatabaseService.SumByType(userId, converter.currency, localDate(startDate, location), localDate(endDate, location).AddDate(0, 0, 1))
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, ServiceErrorFromAppError(appErr)
        }
-	totalExpenses := 0.0
-	totalIncome := 0.0
-	for _, txn := range txns {
-		if txn.Type == "expense" {
-			totalExpenses += txn.Amount
-		} else {
-			totalIncome += txn.Amount
-		}
+	totalExpenses, totalIncome, err := sumTotals(converter, totals)
+	if err != nil {
+		return nil, conversionError(c, err)
 	}
 	netBalance := totalIncome - totalExpenses
 	return &reports.CustomDateRangeSummary{
 		StartDate:     startDate.Format("2006-01-02"),
 		EndDate:       endDate.Format("2006-01-02"),
+		Currency:      converter.currency,
 		TotalExpenses: totalExpenses,
 		TotalIncome:   totalIncome,
 		NetBalance:    netBalance,
 	}, nil
 }
 
-func (s *ReportsService) GetDailyAverageSummary(c *gin.Context, userId uuid.UUID, startDate time.Time, endDate time.Time) (*reports.DailyAverageSummary, *ServiceError) {
-	txns, err := s.transactionDatabaseService.GetTransactionsByDateRange(userId, startDate, endDate)
+func (s *ReportsService) GetDailyAverageSummary(c *gin.Context, userId uuid.UUID, startDate time.Time, endDate time.Time, currency string) (*reports.DailyAverageSummary, *ServiceError) {
+	converter, serviceErr := s.reportConverter(c, userId, currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	location, _, serviceErr := s.reportCalendar(c, userId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// The end date counts in full, and the average is over the same local days the totals cover
+	from, until := localDate(startDate, location), localDate(endDate, location).AddDate(0, 0, 1)
+	daysDiff := daysBetween(from, until)
+	if daysDiff < 1 {
+		appErr := errors.NewBadRequestError("end date must not be before start date", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	totals, err := s.reportDatabaseService.SumByType(userId, converter.currency, from, until)
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, ServiceErrorFromAppError(appErr)
        }
 
-	totalExpenses := 0.0
-	totalIncome := 0.0
-	daysDiff := int(endDate.Sub(startDate).Hours()/24) + 1
-
-	for _, txn := range txns {
-		if txn.Type == "expense" {
-			totalExpenses += txn.Amount
-		} else {
-			totalIncome += txn.Amount
-		}
+	totalExpenses, totalIncome, err := sumTotals(converter, totals)
+	if err != nil {
+		return nil, conversionError(c, err)
 	}
 
-	dailyExpenses := totalExpenses / float64(daysDiff)
-	dailyIncome := totalIncome / float64(daysDiff)
+	dailyExpenses, err := totalExpenses.Div(int64(daysDiff))
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+	dailyIncome, err := totalIncome.Div(int64(daysDiff))
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
 	dailyNetBalance := dailyIncome - dailyExpenses
 
 	return &reports.DailyAverageSummary{
 		Date:          startDate.Format("2006-01-02") + " to " + endDate.Format("2006-01-02"),
+		Currency:      converter.currency,
 		TotalExpenses: dailyExpenses,
 		TotalIncome:   dailyIncome,
 		NetBalance:    dailyNetBalance,
 	}, nil
 }
 
-func (s *ReportsService) GetTopCategories(c *gin.Context, userId uuid.UUID, limit int, transactionType string) ([]*reports.TopCategory, *ServiceError) {
+func (s *ReportsService) GetTopCategories(c *gin.Context, userId uuid.UUID, limit int, transactionType string, currency string) ([]*reports.TopCategory, *ServiceError) {
+	converter, serviceErr := s.reportConverter(c, userId, currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
 	if limit <= 0 {
 		limit = 5
 	}
 
-	// Get all transactions for the user
-	txns, err := s.transactionDatabaseService.GetTransactionsByType(userId, transactionType)
+	// Sum what the user's transactions and splits add to each category, with its name, in one query
+	totals, err := s.reportDatabaseService.SumByCategory(userId, converter.currency, nil, transactionType)
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, ServiceErrorFromAppError(appErr)
        }
 
-	// Group transactions by category
-	categoryTotals := make(map[string]float64)
+	// Group amounts by category, uncategorized ones under an empty ID
+	categoryTotals := make(map[string]models.Money)
 	categoryNames := make(map[string]string)
 
-	for _, txn := range txns {
-		categoryTotals[txn.CategoryID.String()] += txn.Amount
-		// We'll get category names in the next step
-	}
-
-	// Get category names
-	for categoryID := range categoryTotals {
-		parsedId, err := uuid.Parse(categoryID)
-		if err != nil {
-			continue
+	for _, total := range totals {
+		categoryID := ""
+		if total.GroupID != nil {
+			categoryID = total.GroupID.String()
 		}
-		category, err := s.categoryDatabaseService.GetCategoryByID(parsedId, userId)
+		converted, err := convertTotal(converter, total)
 		if err != nil {
-			continue
+			return nil, conversionError(c, err)
 		}
-		if category != nil {
-			categoryNames[categoryID] = category.Name
+		categoryTotals[categoryID] += converted
+		if total.GroupName != nil {
+			categoryNames[categoryID] = *total.GroupName
 		}
 	}
 
@@ -295,6 +425,7 @@ func (s *ReportsService) GetTopCategories(c *gin.Context, userId uuid.UUID, limi
 		categories = append(categories, &reports.TopCategory{
 			CategoryID:   categoryID,
 			CategoryName: categoryName,
+			Currency:     converter.currency,
 			Amount:       amount,
 			Type:         transactionType,
 		})
@@ -323,36 +454,55 @@ func (s *ReportsService) GetTopCategories(c *gin.Context, userId uuid.UUID, limi
 	return categories, nil
 }
 
-func (s *ReportsService) GetAllCategoriesSummary(c *gin.Context, userId uuid.UUID) ([]*reports.CategorySummary, *ServiceError) {
-	categories, err := s.categoryDatabaseService.GetUserCategories(userId)
+func (s *ReportsService) GetAllCategoriesSummary(c *gin.Context, userId uuid.UUID, currency string) ([]*reports.CategorySummary, *ServiceError) {
+	converter, serviceErr := s.reportConverter(c, userId, currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	categories, err := s.categoryDatabaseService.GetUserCategories(userId, database.PageQuery{})
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, ServiceErrorFromAppError(appErr)
        }
 
-	var summaries []*reports.CategorySummary
+	// Sum every transaction and split of the user by category in one query
+	totals, err := s.reportDatabaseService.SumByCategory(userId, converter.currency, nil, "")
+       if err != nil {
+	       appErr := errors.NewInternalError(err, )
+	       c.Error(appErr)
+	       return nil, ServiceErrorFromAppError(appErr)
+       }
 
-	for _, category := range categories {
-		txns, err := s.transactionDatabaseService.GetTransactionsByCategory(userId, category.ID)
-		if err != nil {
+	expensesByCategory := make(map[uuid.UUID]models.Money)
+	incomeByCategory := make(map[uuid.UUID]models.Money)
+	for _, total := range totals {
+		if total.GroupID == nil {
 			continue
 		}
-
-		totalExpenses := 0.0
-		totalIncome := 0.0
-		for _, txn := range txns {
-			if txn.Type == "expense" {
-				totalExpenses += txn.Amount
-			} else {
-				totalIncome += txn.Amount
-			}
+		converted, err := convertTotal(converter, total)
+		if err != nil {
+			return nil, conversionError(c, err)
+		}
+		if total.Type == "expense" {
+			expensesByCategory[*total.GroupID] += converted
+		} else {
+			incomeByCategory[*total.GroupID] += converted
 		}
+	}
+
+	var summaries []*reports.CategorySummary
+
+	for _, category := range categories {
+		totalExpenses := expensesByCategory[category.ID]
+		totalIncome := incomeByCategory[category.ID]
 
 		netBalance := totalIncome - totalExpenses
 		summaries = append(summaries, &reports.CategorySummary{
 			CategoryID:    category.ID,
 			CategoryName:  category.Name,
+			Currency:      converter.currency,
 			TotalExpenses: totalExpenses,
 			TotalIncome:   totalIncome,
 			NetBalance:    netBalance,
@@ -361,3 +511,296 @@ func (s *ReportsService) GetAllCategoriesSummary(c *gin.Context, userId uuid.UUI
 
 	return summaries, nil
 }
+
+func (s *ReportsService) GetAllTagsSummary(c *gin.Context, userId uuid.UUID, currency string) ([]*reports.TagSummary, *ServiceError) {
+	converter, serviceErr := s.reportConverter(c, userId, currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	tags, err := s.tagDatabaseService.GetUserTags(userId, database.PageQuery{})
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	totals, err := s.reportDatabaseService.SumByTag(userId, converter.currency)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	expensesByTag := make(map[uuid.UUID]models.Money)
+	incomeByTag := make(map[uuid.UUID]models.Money)
+	for _, total := range totals {
+		converted, err := convertTotal(converter, total)
+		if err != nil {
+			return nil, conversionError(c, err)
+		}
+		if total.Type == "expense" {
+			expensesByTag[*total.GroupID] += converted
+		} else {
+			incomeByTag[*total.GroupID] += converted
+		}
+	}
+
+	var summaries []*reports.TagSummary
+
+	for _, tag := range tags {
+		totalExpenses := expensesByTag[tag.ID]
+		totalIncome := incomeByTag[tag.ID]
+
+		summaries = append(summaries, &reports.TagSummary{
+			TagID:         tag.ID,
+			TagName:       tag.Name,
+			Currency:      converter.currency,
+			TotalExpenses: totalExpenses,
+			TotalIncome:   totalIncome,
+			NetBalance:    totalIncome - totalExpenses,
+		})
+	}
+
+	return summaries, nil
+}
+
+// GetTimeSeries totals the user's transactions per day, week, month, quarter or year between two
+// dates, both included. Buckets follow the user's timezone and week start unless another timezone
+// is requested.
+func (s *ReportsService) GetTimeSeries(c *gin.Context, userId uuid.UUID, interval string, startDate time.Time, endDate time.Time, groupBy string, timezone string, currency string) (*reports.TimeSeries, *ServiceError) {
+	converter, serviceErr := s.reportConverter(c, userId, currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	location, weekStart, serviceErr := s.reportCalendar(c, userId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	if timezone != "" {
+		requested, err := time.LoadLocation(timezone)
+		if err != nil {
+			appErr := errors.NewBadRequestError("invalid timezone", err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		location = requested
+	}
+	startDate, endDate = localDate(startDate, location), localDate(endDate, location)
+	if groupBy == "" {
+		groupBy = "type"
+	}
+
+	// Lay out every bucket first so those without transactions are reported as zero
+	rangeEnd := endDate.AddDate(0, 0, 1)
+	var buckets []reports.TimeSeriesBucket
+	bucketIndex := make(map[string]int)
+	for start := bucketStart(startDate, interval, weekStart); start.Before(rangeEnd); start = nextBucket(start, interval) {
+		if len(buckets) == maxTimeSeriesBuckets {
+			appErr := errors.NewBadRequestError("too many buckets, use a longer interval or a shorter range", nil)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+
+		first, last := start, nextBucket(start, interval).AddDate(0, 0, -1)
+		if first.Before(startDate) {
+			first = startDate
+		}
+		if last.After(endDate) {
+			last = endDate
+		}
+		bucketIndex[start.Format("2006-01-02")] = len(buckets)
+		buckets = append(buckets, reports.TimeSeriesBucket{
+			CustomDateRangeSummary: reports.CustomDateRangeSummary{
+				StartDate: first.Format("2006-01-02"),
+				EndDate:   last.Format("2006-01-02"),
+				Currency:  converter.currency,
+			},
+		})
+	}
+
+	totals, err := s.reportDatabaseService.SumByBucket(userId, converter.currency, interval, location.String(), weekStart, startDate, rangeEnd, groupBy)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	// Group totals are keyed by bucket and group, transactions without a group under uuid.Nil
+	type cell struct {
+		bucket int
+		group  uuid.UUID
+	}
+	expenses := make(map[cell]models.Money)
+	income := make(map[cell]models.Money)
+	groupNames := make(map[uuid.UUID]string)
+	for _, total := range totals {
+		if total.Bucket == nil {
+			continue
+		}
+		i, ok := bucketIndex[total.Bucket.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		converted, err := convertTotal(converter, total)
+		if err != nil {
+			return nil, conversionError(c, err)
+		}
+
+		key := cell{bucket: i}
+		if total.GroupID != nil {
+			key.group = *total.GroupID
+		}
+		if total.Type == "expense" {
+			buckets[i].TotalExpenses += converted
+			expenses[key] += converted
+		} else {
+			buckets[i].TotalIncome += converted
+			income[key] += converted
+		}
+		if groupBy != "type" {
+			name := ""
+			if total.GroupName != nil {
+				name = *total.GroupName
+			}
+			groupNames[key.group] = name
+		}
+	}
+
+	// Every bucket lists the same groups, by name with the one for ungrouped transactions last
+	groupIDs := make([]uuid.UUID, 0, len(groupNames))
+	for id := range groupNames {
+		groupIDs = append(groupIDs, id)
+	}
+	sort.Slice(groupIDs, func(i, j int) bool {
+		a, b := groupIDs[i], groupIDs[j]
+		if (a == uuid.Nil) != (b == uuid.Nil) {
+			return b == uuid.Nil
+		}
+		if groupNames[a] != groupNames[b] {
+			return groupNames[a] < groupNames[b]
+		}
+		return a.String() < b.String()
+	})
+	for i := range buckets {
+		bucket := &buckets[i]
+		bucket.NetBalance = bucket.TotalIncome - bucket.TotalExpenses
+		for _, id := range groupIDs {
+			key := cell{bucket: i, group: id}
+			group := reports.TimeSeriesGroup{
+				Name:          groupNames[id],
+				TotalExpenses: expenses[key],
+				TotalIncome:   income[key],
+				NetBalance:    income[key] - expenses[key],
+			}
+			if id != uuid.Nil {
+				group.ID = &id
+			}
+			bucket.Groups = append(bucket.Groups, group)
+		}
+	}
+
+	return &reports.TimeSeries{
+		Interval: interval,
+		GroupBy:  groupBy,
+		Timezone: location.String(),
+		Currency: converter.currency,
+		Buckets:  buckets,
+	}, nil
+}
+
+// reportCalendar returns the timezone and first day of the week the user's reports follow
+func (s *ReportsService) reportCalendar(c *gin.Context, userId uuid.UUID) (*time.Location, time.Weekday, *ServiceError) {
+	user, err := s.userDatabaseService.GetUserByID(userId)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, 0, ServiceErrorFromAppError(appErr)
+	}
+	if user == nil {
+		appErr := errors.NewNotFoundError("user", nil)
+		c.Error(appErr)
+		return nil, 0, ServiceErrorFromAppError(appErr)
+	}
+	return user.Location(), user.WeekStart, nil
+}
+
+// reportConverter converts amounts into the requested reporting currency, or into the user's base
+// currency when none was requested
+func (s *ReportsService) reportConverter(c *gin.Context, userId uuid.UUID, currency string) (*currencyConverter, *ServiceError) {
+	currency, serviceErr := resolveCurrency(c, s.userDatabaseService, userId, currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	return newCurrencyConverter(c.Request.Context(), s.exchangeRates, currency), nil
+}
+
+// convertTotal converts a total into the converter's currency, at the rate of its day when it has one
+func convertTotal(converter *currencyConverter, total reports.ReportTotal) (models.Money, error) {
+	var day time.Time
+	if total.Day != nil {
+		day = *total.Day
+	}
+	return converter.convert(total.Amount, total.Currency, day)
+}
+
+// sumTotals adds up the expenses and income among totals in the converter's currency
+func sumTotals(converter *currencyConverter, totals []reports.ReportTotal) (models.Money, models.Money, error) {
+	var totalExpenses, totalIncome models.Money
+	for _, total := range totals {
+		amount, err := convertTotal(converter, total)
+		if err != nil {
+			return 0, 0, err
+		}
+		if total.Type == "expense" {
+			totalExpenses += amount
+		} else {
+			totalIncome += amount
+		}
+	}
+	return totalExpenses, totalIncome, nil
+}
+
+// bucketStart returns the start of the time series bucket containing t, in t's location
+func bucketStart(t time.Time, interval string, weekStart time.Weekday) time.Time {
+	year, month, day := t.Date()
+	switch interval {
+	case "week":
+		return time.Date(year, month, day-(int(t.Weekday())-int(weekStart)+7)%7, 0, 0, 0, 0, t.Location())
+	case "month":
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	case "quarter":
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, t.Location())
+	case "year":
+		return time.Date(year, 1, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// nextBucket returns the start of the bucket after the one starting at start
+func nextBucket(start time.Time, interval string) time.Time {
+	switch interval {
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "month":
+		return start.AddDate(0, 1, 0)
+	case "quarter":
+		return start.AddDate(0, 3, 0)
+	case "year":
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// localDate returns midnight at the start of date's calendar day in location
+func localDate(date time.Time, location *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location)
+}
+
+// daysBetween counts the calendar days from one local midnight to another, rounding away the hour a
+// DST change adds or removes
+func daysBetween(from, until time.Time) int {
+	return int(math.Round(until.Sub(from).Hours() / 24))
+}
@@ -0,0 +1,19 @@
+package services
+
+import "time"
+
+// startScheduler calls run right away and then every interval on its own goroutine.
+// A zero interval disables it.
+func startScheduler(interval time.Duration, run func(now time.Time)) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			run(time.Now())
+		}
+	}()
+}
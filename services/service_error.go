@@ -6,6 +6,8 @@ import "github.com/AlsoShantanuBorkar/budget_max/errors"
 type ServiceError struct {
 	Code    int
 	Message string
+	// Data is optional context for the client, e.g. the records that caused a conflict
+	Data any
 }
 
 func (e *ServiceError) Error() string {
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/database"
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TagServiceInterface interface {
+	CreateTag(c *gin.Context, req *models.CreateTagRequest, userId uuid.UUID) (*models.Tag, *ServiceError)
+	UpdateTag(c *gin.Context, req *models.UpdateTagRequest, tagId uuid.UUID, userId uuid.UUID) (*models.Tag, *ServiceError)
+	DeleteTag(c *gin.Context, tagId uuid.UUID, userId uuid.UUID) *ServiceError
+	GetTagsByUserID(c *gin.Context, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[models.Tag], *ServiceError)
+	GetTagByID(c *gin.Context, tagId uuid.UUID, userId uuid.UUID) (*models.Tag, *ServiceError)
+}
+
+var tagPages = pageSpec[models.Tag]{
+	keys: map[string]sortKey[models.Tag]{
+		"name":       {column: "name", value: func(tag models.Tag) any { return tag.Name }, decode: decodeCursorValue[string]},
+		"created_at": {column: "created_at", value: func(tag models.Tag) any { return tag.CreatedAt }, decode: decodeCursorValue[time.Time]},
+	},
+	defaultSort: "name",
+	id:          func(tag models.Tag) uuid.UUID { return tag.ID },
+}
+
+type TagService struct {
+	databaseService database.TagDatabaseServiceInterface
+}
+
+func NewTagService(dbService database.TagDatabaseServiceInterface) TagServiceInterface {
+	return &TagService{
+		databaseService: dbService,
+	}
+}
+
+func (s *TagService) CreateTag(c *gin.Context, req *models.CreateTagRequest, userId uuid.UUID) (*models.Tag, *ServiceError) {
+	tag := &models.Tag{
+		ID:        uuid.New(),
+		UserID:    userId,
+		Name:      strings.TrimSpace(req.Name),
+		Color:     req.Color,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.databaseService.CreateTag(tag); err != nil {
+		return nil, tagSaveError(c, err)
+	}
+
+	return tag, nil
+}
+
+func (s *TagService) UpdateTag(c *gin.Context, req *models.UpdateTagRequest, tagId uuid.UUID, userId uuid.UUID) (*models.Tag, *ServiceError) {
+	// Fetch existing tag to verify ownership
+	tag, err := s.databaseService.GetTagByID(tagId, userId)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+	if tag == nil {
+		appErr := errors.NewNotFoundError("tag", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	updates := make(map[string]any)
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Color != nil {
+		updates["color"] = req.Color
+	}
+
+	if len(updates) > 0 {
+		if err := s.databaseService.UpdateTag(tagId, updates); err != nil {
+			return nil, tagSaveError(c, err)
+		}
+	}
+
+	// Fetch updated tag
+	updatedTag, err := s.databaseService.GetTagByID(tagId, userId)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return updatedTag, nil
+}
+
+// DeleteTag removes the tag from every transaction that has it and deletes it
+func (s *TagService) DeleteTag(c *gin.Context, tagId uuid.UUID, userId uuid.UUID) *ServiceError {
+	tag, err := s.databaseService.GetTagByID(tagId, userId)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+	if tag == nil {
+		appErr := errors.NewNotFoundError("tag", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if err := s.databaseService.DeleteTag(tagId); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	return nil
+}
+
+func (s *TagService) GetTagsByUserID(c *gin.Context, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[models.Tag], *ServiceError) {
+	query, serviceErr := tagPages.query(c, pageReq)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	tags, err := s.databaseService.GetUserTags(userId, query)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return tagPages.page(c, pageReq, query, tags)
+}
+
+func (s *TagService) GetTagByID(c *gin.Context, tagId uuid.UUID, userId uuid.UUID) (*models.Tag, *ServiceError) {
+	tag, err := s.databaseService.GetTagByID(tagId, userId)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+	if tag == nil {
+		appErr := errors.NewNotFoundError("tag", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return tag, nil
+}
+
+// tagSaveError reports a duplicate tag name as a conflict and anything else as an internal error
+func tagSaveError(c *gin.Context, err error) *ServiceError {
+	appErr := errors.NewInternalError(err)
+	if database.IsUniqueViolation(err) {
+		appErr = errors.NewConflictError("a tag with this name already exists", err)
+	}
+	c.Error(appErr)
+	return ServiceErrorFromAppError(appErr)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"strings"
 	"time"
 
 	"github.com/AlsoShantanuBorkar/budget_max/database"
@@ -15,23 +16,45 @@ type TransactionServiceInterface interface {
 	CreateTransaction(c *gin.Context, req *models.CreateTransactionRequest, userId uuid.UUID) (*models.Transaction, *ServiceError)
 	UpdateTransaction(c *gin.Context, req *models.UpdateTransactionRequest, txnId uuid.UUID, userId uuid.UUID) (*models.Transaction, *ServiceError)
 	DeleteTransaction(c *gin.Context, txnId uuid.UUID, userId uuid.UUID) *ServiceError
-	GetTransactionsByUserID(c *gin.Context, userId uuid.UUID) ([]*models.Transaction, *ServiceError)
+	GetTransactionsByUserID(c *gin.Context, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError)
 	GetTransactionByID(c *gin.Context, txnID uuid.UUID, userId uuid.UUID) (*models.Transaction, *ServiceError)
-	GetTransactionsByBudget(c *gin.Context, budgetID uuid.UUID, userId uuid.UUID) ([]*models.Transaction, *ServiceError)
-	GetTransactionsByCategory(c *gin.Context, categoryID uuid.UUID, userId uuid.UUID) ([]*models.Transaction, *ServiceError)
-	GetTransactionsByDateRange(c *gin.Context, startDate, endDate time.Time, userId uuid.UUID) ([]*models.Transaction, *ServiceError)
-	GetTransactionsByType(c *gin.Context, transactionType string, userId uuid.UUID) ([]*models.Transaction, *ServiceError)
-	GetTransactionsByAmountRange(c *gin.Context, minAmount, maxAmount float64, userId uuid.UUID) ([]*models.Transaction, *ServiceError)
-	GetTransactionsWithFilters(c *gin.Context, filters map[string]interface{}, userId uuid.UUID) ([]*models.Transaction, *ServiceError)
+	GetTransactionsByBudget(c *gin.Context, budgetID uuid.UUID, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError)
+	GetTransactionsByCategory(c *gin.Context, categoryID uuid.UUID, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError)
+	GetTransactionsByDateRange(c *gin.Context, startDate, endDate time.Time, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError)
+	GetTransactionsByType(c *gin.Context, transactionType string, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError)
+	GetTransactionsByAmountRange(c *gin.Context, minAmount, maxAmount models.Money, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError)
+	GetTransactionsWithFilters(c *gin.Context, filters map[string]interface{}, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError)
+}
+
+var transactionPages = pageSpec[*models.Transaction]{
+	keys: map[string]sortKey[*models.Transaction]{
+		"date":       {column: "date", value: func(t *models.Transaction) any { return t.Date }, decode: decodeCursorValue[time.Time]},
+		"amount":     {column: "amount", value: func(t *models.Transaction) any { return t.Amount }, decode: decodeCursorValue[models.Money]},
+		"created_at": {column: "created_at", value: func(t *models.Transaction) any { return t.CreatedAt }, decode: decodeCursorValue[time.Time]},
+		// Only available when searching, see listTransactions
+		"relevance": {column: "rank", value: func(t *models.Transaction) any { return t.Rank }, decode: decodeCursorValue[float64]},
+	},
+	defaultSort: "-date",
+	id:          func(t *models.Transaction) uuid.UUID { return t.ID },
 }
 
 type TransactionService struct {
 	transactionDatabase database.TransactionDatabaseServiceInterface
+	categoryDatabase    database.CategoryDatabaseServiceInterface
+	budgetDatabase      database.BudgetDatabaseServiceInterface
+	tagDatabase         database.TagDatabaseServiceInterface
+	userDatabase        database.UserDatabaseServiceInterface
+	notificationService NotificationServiceInterface
 }
 
-func NewTransactionService(dbService database.TransactionDatabaseServiceInterface) *TransactionService {
+func NewTransactionService(dbService database.TransactionDatabaseServiceInterface, categoryDBService database.CategoryDatabaseServiceInterface, budgetDBService database.BudgetDatabaseServiceInterface, tagDBService database.TagDatabaseServiceInterface, userDBService database.UserDatabaseServiceInterface, notificationService NotificationServiceInterface) *TransactionService {
 	return &TransactionService{
 		transactionDatabase: dbService,
+		categoryDatabase:    categoryDBService,
+		budgetDatabase:      budgetDBService,
+		tagDatabase:         tagDBService,
+		userDatabase:        userDBService,
+		notificationService: notificationService,
 	}
 }
 
@@ -65,10 +88,29 @@ func (s *TransactionService) CreateTransaction(c *gin.Context, req *models.Creat
 		budgetID = &budID
 	}
 
+	if serviceErr := s.checkReferences(c, userId, categoryID, budgetID); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	txnID := uuid.New()
+	splits, serviceErr := s.buildSplits(c, req.Splits, txnID, req.Amount, userId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	tags, serviceErr := s.transactionTags(c, req.TagIDs, userId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	currency, serviceErr := resolveCurrency(c, s.userDatabase, userId, req.Currency)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
 	txn := &models.Transaction{
-		ID:         uuid.New(),
+		ID:         txnID,
 		UserID:     userId,
 		Amount:     req.Amount,
+		Currency:   currency,
 		Type:       req.Type,
 		Name:       req.Name,
 		Note:       req.Note,
@@ -76,6 +118,8 @@ func (s *TransactionService) CreateTransaction(c *gin.Context, req *models.Creat
 		CategoryID: categoryID,
 		BudgetID:   budgetID,
 		CreatedAt:  time.Now(),
+		Splits:     splits,
+		Tags:       tags,
 	}
 
 	if err := s.transactionDatabase.CreateTransaction(txn); err != nil {
@@ -84,12 +128,14 @@ func (s *TransactionService) CreateTransaction(c *gin.Context, req *models.Creat
 		return nil, ServiceErrorFromAppError(appErr)
 	}
 
+	s.notificationService.CheckTransactionBudgets(txn)
+
 	return txn, nil
 }
 
 func (s *TransactionService) UpdateTransaction(c *gin.Context, req *models.UpdateTransactionRequest, txnId uuid.UUID, userId uuid.UUID) (*models.Transaction, *ServiceError) {
 	// Fetch existing transaction to verify ownership
-	_, err := s.transactionDatabase.GetTransactionByID(txnId, userId)
+	existing, err := s.transactionDatabase.GetTransactionByID(txnId, userId)
 	if err != nil {
 		appErr := errors.NewNotFoundError("transaction", err,)
 		c.Error(appErr)
@@ -103,6 +149,9 @@ func (s *TransactionService) UpdateTransaction(c *gin.Context, req *models.Updat
 	if req.Amount != nil {
 		updates["amount"] = *req.Amount
 	}
+	if req.Currency != nil {
+		updates["currency"] = *req.Currency
+	}
 	if req.Type != nil {
 		updates["type"] = *req.Type
 	}
@@ -125,6 +174,9 @@ func (s *TransactionService) UpdateTransaction(c *gin.Context, req *models.Updat
 			c.Error(appErr)
 			return nil, ServiceErrorFromAppError(appErr)
 		}
+		if serviceErr := s.checkReferences(c, userId, &parsedCategoryID, nil); serviceErr != nil {
+			return nil, serviceErr
+		}
 		updates["category_id"] = parsedCategoryID
 	}
 	if req.BudgetID != nil {
@@ -134,16 +186,57 @@ func (s *TransactionService) UpdateTransaction(c *gin.Context, req *models.Updat
 			c.Error(appErr)
 			return nil, ServiceErrorFromAppError(appErr)
 		}
+		if serviceErr := s.checkReferences(c, userId, nil, &parsedBudgetID); serviceErr != nil {
+			return nil, serviceErr
+		}
 		updates["budget_id"] = parsedBudgetID
 	}
 
-	// Save updated transaction
-	err = s.transactionDatabase.UpdateTransaction(txnId, updates)
+	amount := existing.Amount
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+
+	var tags []models.Tag
+	if req.TagIDs != nil {
+		var serviceErr *ServiceError
+		tags, serviceErr = s.transactionTags(c, req.TagIDs, userId)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
+	}
+
+	// Save updated transaction, replacing its splits when new ones were sent
+	if req.Splits != nil {
+		splits, serviceErr := s.buildSplits(c, req.Splits, txnId, amount, userId)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
+		err = s.transactionDatabase.UpdateTransactionAndSplits(txnId, updates, splits)
+	} else {
+		if len(existing.Splits) > 0 && splitsTotal(existing.Splits) != amount {
+			appErr := errors.NewBadRequestError("splits must add up to the transaction amount, send them again with the new amount", nil)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		err = s.transactionDatabase.UpdateTransaction(txnId, updates)
+	}
        if err != nil {
 	       appErr := errors.NewInternalError(err, )
 	       c.Error(appErr)
 	       return nil, ServiceErrorFromAppError(appErr)
        }
+	if req.TagIDs != nil {
+		tagIDs := make([]uuid.UUID, len(tags))
+		for i, tag := range tags {
+			tagIDs[i] = tag.ID
+		}
+		if err := s.transactionDatabase.SetTransactionTags(txnId, tagIDs); err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+	}
 
 	// Fetch updated transaction
 	updatedTransaction, err := s.transactionDatabase.GetTransactionByID(txnId, userId)
@@ -153,6 +246,8 @@ func (s *TransactionService) UpdateTransaction(c *gin.Context, req *models.Updat
 	       return nil, ServiceErrorFromAppError(appErr)
        }
 
+	s.notificationService.CheckTransactionBudgets(updatedTransaction)
+
 	return updatedTransaction, nil
 }
 
@@ -175,15 +270,8 @@ func (s *TransactionService) DeleteTransaction(c *gin.Context, txnId uuid.UUID,
 	return nil
 }
 
-func (s *TransactionService) GetTransactionsByUserID(c *gin.Context, userId uuid.UUID) ([]*models.Transaction, *ServiceError) {
-	txns, err := s.transactionDatabase.GetTransactionsByUser(userId)
-       if err != nil {
-	       appErr := errors.NewInternalError(err, )
-	       c.Error(appErr)
-	       return nil, ServiceErrorFromAppError(appErr)
-       }
-
-	return txns, nil
+func (s *TransactionService) GetTransactionsByUserID(c *gin.Context, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError) {
+	return s.listTransactions(c, map[string]interface{}{}, pageReq, userId)
 }
 
 func (s *TransactionService) GetTransactionByID(c *gin.Context, txnID uuid.UUID, userId uuid.UUID) (*models.Transaction, *ServiceError) {
@@ -197,80 +285,191 @@ func (s *TransactionService) GetTransactionByID(c *gin.Context, txnID uuid.UUID,
 	return txn, nil
 }
 
-func (s *TransactionService) GetTransactionsByBudget(c *gin.Context, budgetID uuid.UUID, userId uuid.UUID) ([]*models.Transaction, *ServiceError) {
-	txns, err := s.transactionDatabase.GetTransactionsByBudget(userId, budgetID)
-       if err != nil {
-	       appErr := errors.NewInternalError(err, )
-	       c.Error(appErr)
-	       return nil, ServiceErrorFromAppError(appErr)
-       }
-
-	return txns, nil
+func (s *TransactionService) GetTransactionsByBudget(c *gin.Context, budgetID uuid.UUID, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError) {
+	return s.listTransactions(c, map[string]interface{}{"budget_id": budgetID}, pageReq, userId)
 }
 
-func (s *TransactionService) GetTransactionsByCategory(c *gin.Context, categoryID uuid.UUID, userId uuid.UUID) ([]*models.Transaction, *ServiceError) {
-       txns, err := s.transactionDatabase.GetTransactionsByCategory(userId, categoryID)
-       if err != nil {
-	       appErr := errors.NewInternalError(err, )
-	       c.Error(appErr)
-	       return nil, ServiceErrorFromAppError(appErr)
-       }
-
-	return txns, nil
+func (s *TransactionService) GetTransactionsByCategory(c *gin.Context, categoryID uuid.UUID, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError) {
+	return s.listTransactions(c, map[string]interface{}{"category_id": categoryID}, pageReq, userId)
 }
 
-func (s *TransactionService) GetTransactionsByDateRange(c *gin.Context, startDate, endDate time.Time, userId uuid.UUID) ([]*models.Transaction, *ServiceError) {
-       txns, err := s.transactionDatabase.GetTransactionsByDateRange(userId, startDate, endDate)
-       if err != nil {
-	       appErr := errors.NewInternalError(err, )
-	       c.Error(appErr)
-	       return nil, ServiceErrorFromAppError(appErr)
-       }
-
-	return txns, nil
+func (s *TransactionService) GetTransactionsByDateRange(c *gin.Context, startDate, endDate time.Time, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError) {
+	return s.listTransactions(c, map[string]interface{}{"start_date": startDate, "end_date": endDate}, pageReq, userId)
 }
 
-func (s *TransactionService) GetTransactionsByType(c *gin.Context, transactionType string, userId uuid.UUID) ([]*models.Transaction, *ServiceError) {
+func (s *TransactionService) GetTransactionsByType(c *gin.Context, transactionType string, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError) {
 	if transactionType != "expense" && transactionType != "income" {
 		appErr := errors.NewBadRequestError("invalid transaction type", nil)
 		c.Error(appErr)
 		return nil, ServiceErrorFromAppError(appErr)
 	}
 
-       txns, err := s.transactionDatabase.GetTransactionsByType(userId, transactionType)
-       if err != nil {
-	       appErr := errors.NewInternalError(err, )
-	       c.Error(appErr)
-	       return nil, ServiceErrorFromAppError(appErr)
-       }
-
-	return txns, nil
+	return s.listTransactions(c, map[string]interface{}{"type": transactionType}, pageReq, userId)
 }
 
-func (s *TransactionService) GetTransactionsByAmountRange(c *gin.Context, minAmount, maxAmount float64, userId uuid.UUID) ([]*models.Transaction, *ServiceError) {
+func (s *TransactionService) GetTransactionsByAmountRange(c *gin.Context, minAmount, maxAmount models.Money, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError) {
 	if minAmount < 0 || maxAmount < 0 || minAmount > maxAmount {
 		appErr := errors.NewBadRequestError("invalid amount range", nil,)
 		c.Error(appErr)
 		return nil, ServiceErrorFromAppError(appErr)
 	}
 
-       txns, err := s.transactionDatabase.GetTransactionsByAmountRange(userId, minAmount, maxAmount)
-       if err != nil {
-	       appErr := errors.NewInternalError(err, )
-	       c.Error(appErr)
-	       return nil, ServiceErrorFromAppError(appErr)
-       }
+	return s.listTransactions(c, map[string]interface{}{"min_amount": minAmount, "max_amount": maxAmount}, pageReq, userId)
+}
 
-	return txns, nil
+func (s *TransactionService) GetTransactionsWithFilters(c *gin.Context, filters map[string]interface{}, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError) {
+	return s.listTransactions(c, filters, pageReq, userId)
 }
 
-func (s *TransactionService) GetTransactionsWithFilters(c *gin.Context, filters map[string]interface{}, userId uuid.UUID) ([]*models.Transaction, *ServiceError) {
-       txns, err := s.transactionDatabase.GetTransactionsWithFilters(userId, filters)
-       if err != nil {
-	       appErr := errors.NewInternalError(err, )
-	       c.Error(appErr)
-	       return nil, ServiceErrorFromAppError(appErr)
-       }
+// listTransactions returns one page of the user's transactions matching filters
+func (s *TransactionService) listTransactions(c *gin.Context, filters map[string]interface{}, pageReq *models.PageRequest, userId uuid.UUID) (*models.Page[*models.Transaction], *ServiceError) {
+	// Search results come most relevant first unless the client picks another order
+	_, searching := filters["q"]
+	if searching && pageReq.Sort == "" {
+		pageReq = &models.PageRequest{Cursor: pageReq.Cursor, Limit: pageReq.Limit, Sort: "-relevance"}
+	}
+	if !searching && strings.TrimPrefix(pageReq.Sort, "-") == "relevance" {
+		appErr := errors.NewBadRequestError("sorting by relevance requires a search query", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	query, serviceErr := transactionPages.query(c, pageReq)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	txns, err := s.transactionDatabase.GetTransactionsWithFilters(userId, filters, query)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return transactionPages.page(c, pageReq, query, txns)
+}
+
+func (s *TransactionService) checkReferences(c *gin.Context, userId uuid.UUID, categoryID *uuid.UUID, budgetID *uuid.UUID) *ServiceError {
+	return checkTransactionReferences(c, s.categoryDatabase, s.budgetDatabase, userId, categoryID, budgetID)
+}
+
+// buildSplits validates the requested splits of a transaction: they must add up to its amount and
+// use the user's own categories
+func (s *TransactionService) buildSplits(c *gin.Context, reqs []models.SplitRequest, txnID uuid.UUID, amount models.Money, userId uuid.UUID) ([]models.TransactionSplit, *ServiceError) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	splits := make([]models.TransactionSplit, len(reqs))
+	for i, req := range reqs {
+		var categoryID *uuid.UUID
+		if req.CategoryID != "" {
+			parsedCategoryID, err := uuid.Parse(req.CategoryID)
+			if err != nil {
+				appErr := errors.NewBadRequestError("invalid split category ID", err)
+				c.Error(appErr)
+				return nil, ServiceErrorFromAppError(appErr)
+			}
+			if serviceErr := s.checkReferences(c, userId, &parsedCategoryID, nil); serviceErr != nil {
+				return nil, serviceErr
+			}
+			categoryID = &parsedCategoryID
+		}
+
+		splits[i] = models.TransactionSplit{
+			ID:            uuid.New(),
+			TransactionID: txnID,
+			CategoryID:    categoryID,
+			Amount:        req.Amount,
+			Note:          req.Note,
+			CreatedAt:     now,
+		}
+	}
+
+	if splitsTotal(splits) != amount {
+		appErr := errors.NewBadRequestError("splits must add up to the transaction amount", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return splits, nil
+}
+
+// transactionTags resolves the tags to put on a transaction, all of which must belong to the user
+func (s *TransactionService) transactionTags(c *gin.Context, tagIDs []string, userId uuid.UUID) ([]models.Tag, *ServiceError) {
+	if len(tagIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(tagIDs))
+	seen := make(map[uuid.UUID]bool, len(tagIDs))
+	for _, tagIDStr := range tagIDs {
+		tagID, err := uuid.Parse(tagIDStr)
+		if err != nil {
+			appErr := errors.NewBadRequestError("invalid tag ID", err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+		if !seen[tagID] {
+			seen[tagID] = true
+			ids = append(ids, tagID)
+		}
+	}
+
+	tags, err := s.tagDatabase.GetTagsByIDs(userId, ids)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+	if len(tags) != len(ids) {
+		appErr := errors.NewBadRequestError("tag not found", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return tags, nil
+}
+
+func splitsTotal(splits []models.TransactionSplit) models.Money {
+	var total models.Money
+	for _, split := range splits {
+		total += split.Amount
+	}
+	return total
+}
+
+// checkTransactionReferences makes sure the category and budget a transaction points at exist and
+// belong to the same user. Nil IDs are skipped.
+func checkTransactionReferences(c *gin.Context, categoryDatabase database.CategoryDatabaseServiceInterface, budgetDatabase database.BudgetDatabaseServiceInterface, userId uuid.UUID, categoryID *uuid.UUID, budgetID *uuid.UUID) *ServiceError {
+	if categoryID != nil {
+		category, err := categoryDatabase.GetCategoryByID(*categoryID, userId)
+		if err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return ServiceErrorFromAppError(appErr)
+		}
+		if category == nil {
+			appErr := errors.NewBadRequestError("category not found", nil)
+			c.Error(appErr)
+			return ServiceErrorFromAppError(appErr)
+		}
+	}
 
-	return txns, nil
+	if budgetID != nil {
+		budget, err := budgetDatabase.GetBudgetByID(*budgetID, userId)
+		if err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return ServiceErrorFromAppError(appErr)
+		}
+		if budget == nil {
+			appErr := errors.NewBadRequestError("budget not found", nil)
+			c.Error(appErr)
+			return ServiceErrorFromAppError(appErr)
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/config"
+	"github.com/AlsoShantanuBorkar/budget_max/database"
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/AlsoShantanuBorkar/budget_max/mailer"
+	"github.com/AlsoShantanuBorkar/budget_max/models"
+	"github.com/AlsoShantanuBorkar/budget_max/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// EmailChangeTokenTTL is how long the confirmation link sent to a new email address stays valid
+const EmailChangeTokenTTL = time.Hour
+
+type UserServiceInterface interface {
+	GetProfile(c *gin.Context, userId uuid.UUID) (*models.User, *ServiceError)
+	UpdateProfile(c *gin.Context, req *models.UpdateProfileRequest, userId uuid.UUID) (*models.User, *ServiceError)
+	ChangePassword(c *gin.Context, req *models.ChangePasswordRequest, userId uuid.UUID, sessionId uuid.UUID) *ServiceError
+	RequestEmailChange(c *gin.Context, req *models.ChangeEmailRequest, userId uuid.UUID) *ServiceError
+	ConfirmEmailChange(c *gin.Context, req *models.ConfirmEmailChangeRequest) *ServiceError
+	DeleteAccount(c *gin.Context, userId uuid.UUID) *ServiceError
+}
+
+type UserService struct {
+	userDatabaseService             database.UserDatabaseServiceInterface
+	emailChangeTokenDatabaseService database.EmailChangeTokenDatabaseServiceInterface
+	authService                     AuthServiceInterface
+	mailer                          mailer.Mailer
+	config                          *config.AppConfig
+	redisClient                     *redis.Client
+}
+
+func NewUserService(userDBService database.UserDatabaseServiceInterface, emailChangeTokenDBService database.EmailChangeTokenDatabaseServiceInterface, authService AuthServiceInterface, mailer mailer.Mailer, config *config.AppConfig, redisClient *redis.Client) UserServiceInterface {
+	return &UserService{
+		userDatabaseService:             userDBService,
+		emailChangeTokenDatabaseService: emailChangeTokenDBService,
+		authService:                     authService,
+		mailer:                          mailer,
+		config:                          config,
+		redisClient:                     redisClient,
+	}
+}
+
+func (s *UserService) GetProfile(c *gin.Context, userId uuid.UUID) (*models.User, *ServiceError) {
+	user, err := s.userDatabaseService.GetUserByID(userId)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	if user == nil {
+		appErr := errors.NewNotFoundError("user", nil)
+		c.Error(appErr)
+		return nil, ServiceErrorFromAppError(appErr)
+	}
+
+	return user, nil
+}
+
+func (s *UserService) UpdateProfile(c *gin.Context, req *models.UpdateProfileRequest, userId uuid.UUID) (*models.User, *ServiceError) {
+	updates := make(map[string]any)
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.BaseCurrency != nil {
+		updates["base_currency"] = *req.BaseCurrency
+	}
+	if req.Timezone != nil {
+		updates["timezone"] = *req.Timezone
+	}
+	if req.WeekStart != nil {
+		updates["week_start"] = *req.WeekStart
+	}
+
+	if len(updates) > 0 {
+		if err := s.userDatabaseService.UpdateUser(userId, updates); err != nil {
+			appErr := errors.NewInternalError(err)
+			c.Error(appErr)
+			return nil, ServiceErrorFromAppError(appErr)
+		}
+	}
+
+	return s.GetProfile(c, userId)
+}
+
+// ChangePassword replaces the password after checking the current one and signs out every other session
+func (s *UserService) ChangePassword(c *gin.Context, req *models.ChangePasswordRequest, userId uuid.UUID, sessionId uuid.UUID) *ServiceError {
+	user, serviceErr := s.GetProfile(c, userId)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	if serviceErr := s.checkPassword(c, user, req.CurrentPassword); serviceErr != nil {
+		return serviceErr
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if err := s.userDatabaseService.UpdateUser(userId, map[string]any{
+		"password": hashedPassword,
+	}); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if _, serviceErr := s.authService.RevokeOtherSessions(c, userId, sessionId); serviceErr != nil {
+		return serviceErr
+	}
+
+	return nil
+}
+
+// RequestEmailChange emails a single use confirmation link to the new address. The email
+// on the account only changes once that link is used.
+func (s *UserService) RequestEmailChange(c *gin.Context, req *models.ChangeEmailRequest, userId uuid.UUID) *ServiceError {
+	user, serviceErr := s.GetProfile(c, userId)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	if serviceErr := s.checkPassword(c, user, req.Password); serviceErr != nil {
+		return serviceErr
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		appErr := errors.NewBadRequestError("new email must differ from the current one", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if serviceErr := s.ensureEmailAvailable(c, newEmail); serviceErr != nil {
+		return serviceErr
+	}
+
+	// Only the most recent link should work
+	if err := s.emailChangeTokenDatabaseService.InvalidateEmailChangeTokensByUserID(userId); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	rawToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	now := time.Now()
+	changeToken := models.EmailChangeToken{
+		ID:        uuid.New(),
+		UserID:    userId,
+		NewEmail:  newEmail,
+		Token:     utils.HashToken(rawToken),
+		ExpiresAt: now.Add(EmailChangeTokenTTL),
+		CreatedAt: now,
+	}
+
+	if err := s.emailChangeTokenDatabaseService.CreateEmailChangeToken(&changeToken); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	err = s.mailer.Send(c.Request.Context(), mailer.Message{
+		To:      newEmail,
+		Subject: "Confirm your new BudgetMax email address",
+		Body: fmt.Sprintf("We received a request to use this address for your BudgetMax account.\n\nUse the link below within %d minutes to confirm it:\n%s/confirm-email?token=%s\n\nIf you did not request this, you can ignore this email.",
+			int(EmailChangeTokenTTL.Minutes()), s.config.AppURL, rawToken),
+	})
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange consumes a confirmation token and moves the account to the new address
+func (s *UserService) ConfirmEmailChange(c *gin.Context, req *models.ConfirmEmailChangeRequest) *ServiceError {
+	changeToken, err := s.emailChangeTokenDatabaseService.GetEmailChangeTokenByToken(utils.HashToken(req.Token))
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if changeToken == nil || changeToken.Used || changeToken.ExpiresAt.Before(time.Now()) {
+		appErr := errors.NewBadRequestError("invalid or expired confirmation token", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	consumed, err := s.emailChangeTokenDatabaseService.MarkEmailChangeTokenUsed(changeToken.ID)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if !consumed {
+		appErr := errors.NewBadRequestError("invalid or expired confirmation token", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	user, serviceErr := s.GetProfile(c, changeToken.UserID)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	// Someone may have signed up with the address since the link was sent
+	if serviceErr := s.ensureEmailAvailable(c, changeToken.NewEmail); serviceErr != nil {
+		return serviceErr
+	}
+
+	// Following the emailed link proves ownership of the new address
+	if err := s.userDatabaseService.UpdateUser(user.ID, map[string]any{
+		"email":             changeToken.NewEmail,
+		"email_verified":    true,
+		"email_verified_at": time.Now(),
+	}); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	// Let the previous address know in case the change was not made by its owner
+	err = s.mailer.Send(c.Request.Context(), mailer.Message{
+		To:      user.Email,
+		Subject: "Your BudgetMax email address was changed",
+		Body:    fmt.Sprintf("The email address on your BudgetMax account was changed to %s.\n\nIf you did not make this change, reset your password immediately.", changeToken.NewEmail),
+	})
+	if err != nil {
+		utils.GetLogger().Error().Err(err).Str("user_id", user.ID.String()).Msg("Failed to send email change notice")
+	}
+
+	return nil
+}
+
+// DeleteAccount signs the user out everywhere and removes the account along with all of its data
+func (s *UserService) DeleteAccount(c *gin.Context, userId uuid.UUID) *ServiceError {
+	if serviceErr := s.authService.RevokeAllSessions(c, userId); serviceErr != nil {
+		return serviceErr
+	}
+
+	if err := s.userDatabaseService.DeleteUserWithData(userId); err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	return nil
+}
+
+// checkPassword verifies the user's password, counting failures towards the login lockout
+func (s *UserService) checkPassword(c *gin.Context, user *models.User, password string) *ServiceError {
+	if err := utils.CheckPasswordHash(password, user.Password); err != nil {
+		if trackErr := utils.CheckAndTrackLoginAttempts(user.Email, s.redisClient, c.Request.Context()); trackErr != nil {
+			appErr := errors.NewTooManyRequestsError(trackErr.Error(), trackErr)
+			c.Error(appErr)
+			return ServiceErrorFromAppError(appErr)
+		}
+		appErr := errors.NewUnauthorizedError("invalid password", err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	utils.ResetLoginAttempts(user.Email, s.redisClient, c.Request.Context())
+	return nil
+}
+
+func (s *UserService) ensureEmailAvailable(c *gin.Context, email string) *ServiceError {
+	existingUser, err := s.userDatabaseService.GetUserByEmail(email)
+	if err != nil {
+		appErr := errors.NewInternalError(err)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	if existingUser != nil {
+		appErr := errors.NewConflictError("user with this email already exists", nil)
+		c.Error(appErr)
+		return ServiceErrorFromAppError(appErr)
+	}
+
+	return nil
+}
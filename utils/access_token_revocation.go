@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func revokedSessionKey(sessionID uuid.UUID) string {
+	return fmt.Sprintf("revoked_session:%s", sessionID)
+}
+
+// RevokeSessionAccessTokens blacklists every access token issued for the session. The entry only
+// needs to outlive the tokens themselves, so it expires after AccessTokenTTL.
+func RevokeSessionAccessTokens(sessionID uuid.UUID, redisClient *redis.Client, ctx context.Context) error {
+	if err := redisClient.Set(ctx, revokedSessionKey(sessionID), "1", AccessTokenTTL).Err(); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	return nil
+}
+
+// IsSessionAccessRevoked reports whether access tokens of the session were revoked before expiring
+func IsSessionAccessRevoked(sessionID uuid.UUID, redisClient *redis.Client, ctx context.Context) (bool, error) {
+	exists, err := redisClient.Exists(ctx, revokedSessionKey(sessionID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check access token revocation: %w", err)
+	}
+	return exists == 1, nil
+}
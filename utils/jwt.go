@@ -1,14 +1,27 @@
 package utils
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/AlsoShantanuBorkar/budget_max/config"
 	"github.com/AlsoShantanuBorkar/budget_max/models"
 	"github.com/AlsoShantanuBorkar/budget_max/models/auth"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+const (
+	JWTIssuer = "budget_max"
+	// TwoFactorAudience scopes the short lived token handed out between password and TOTP checks
+	TwoFactorAudience = "budget_max:2fa"
+	// AccessAudience scopes stateless access tokens accepted by AuthMiddleware
+	AccessAudience = "budget_max:api"
+)
+
+// AccessTokenTTL is how long a session, and any access token issued for it, stays valid
+const AccessTokenTTL = 15 * time.Minute
+
 func GenerateJWT(user *models.User, config *config.AppConfig) (string, error) {
 	claims := &auth.TwoFAClaims{
 		UserID: user.ID,
@@ -18,25 +31,83 @@ func GenerateJWT(user *models.User, config *config.AppConfig) (string, error) {
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 5)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "budget_max",
+			Issuer:    JWTIssuer,
 			Subject:   user.Email,
+			Audience:  jwt.ClaimStrings{TwoFactorAudience},
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.JWTSecret))
+	return signJWT(claims)
 }
 
 func VerifyJWT(token string, config *config.AppConfig) (bool, *models.TwoFAClaims, error) {
 	claims := &models.TwoFAClaims{}
 
-	parsedToken, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
-		return []byte(config.JWTSecret), nil
-	})
-
+	parsedToken, err := parseJWT(token, claims, TwoFactorAudience)
 	if err != nil {
 		return false, nil, err
 	}
 
 	return parsedToken.Valid, claims, nil
 }
+
+// GenerateAccessJWT issues a stateless access token bound to the session it was created for
+func GenerateAccessJWT(user *models.User, session *models.Session, config *config.AppConfig) (string, error) {
+	claims := &auth.AccessTokenClaims{
+		UserID:    user.ID,
+		SessionID: session.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(session.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    JWTIssuer,
+			Subject:   user.ID.String(),
+			Audience:  jwt.ClaimStrings{AccessAudience},
+		},
+	}
+
+	return signJWT(claims)
+}
+
+// VerifyAccessJWT checks the signature, key ID, audience and expiry of an access token
+func VerifyAccessJWT(token string, config *config.AppConfig) (*models.AccessTokenClaims, error) {
+	claims := &models.AccessTokenClaims{}
+
+	parsedToken, err := parseJWT(token, claims, AccessAudience)
+	if err != nil {
+		return nil, err
+	}
+
+	if !parsedToken.Valid {
+		return nil, fmt.Errorf("invalid access token")
+	}
+
+	return claims, nil
+}
+
+func signJWT(claims jwt.Claims) (string, error) {
+	key := GetSigningKeys().Current()
+	token := jwt.NewWithClaims(key.signingMethod(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
+}
+
+func parseJWT(token string, claims jwt.Claims, audience string) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := GetSigningKeys().Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+		}
+		return key.public, nil
+	},
+		jwt.WithValidMethods([]string{SigningAlgorithmHS256, SigningAlgorithmRS256, SigningAlgorithmEdDSA}),
+		jwt.WithIssuer(JWTIssuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+}
@@ -47,6 +47,17 @@ func CheckAndTrackLoginAttempts(email string, redisClient *redis.Client, ctx con
 	return nil
 }
 
+// IsLoginLocked reports whether too many failed attempts have locked the account for now
+func IsLoginLocked(email string, redisClient *redis.Client, ctx context.Context) (bool, error) {
+	lockKey := fmt.Sprintf("login_lock:%s", email)
+
+	isLocked, err := redisClient.Exists(ctx, lockKey).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check login lock: %w", err)
+	}
+	return isLocked == 1, nil
+}
+
 func ResetLoginAttempts(email string, redisClient *redis.Client, ctx context.Context) {
 	attemptKey := fmt.Sprintf("login_attempts:%s", email)
 	lockKey := fmt.Sprintf("login_lock:%s", email)
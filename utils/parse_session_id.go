@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"net/http"
+
+	"github.com/AlsoShantanuBorkar/budget_max/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func ParseSessionID(c *gin.Context) (uuid.UUID, bool) {
+	sessionIdRaw, exists := c.Get("session_id")
+	if !exists {
+		appErr := errors.NewUnauthorizedError("Session ID not found in context", nil)
+		c.Error(appErr)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session ID not found"})
+		c.Abort()
+		return uuid.UUID{}, false
+	}
+
+	sessionId, ok := sessionIdRaw.(uuid.UUID)
+	if !ok {
+		appErr := errors.NewUnauthorizedError("Invalid session ID type", nil)
+		c.Error(appErr)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid session ID type"})
+		c.Abort()
+		return uuid.UUID{}, false
+	}
+
+	return sessionId, true
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitResult describes the state of a client's window after a request was counted
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAt is when the oldest counted request leaves the window and frees a slot
+	ResetAt time.Time
+}
+
+// RateLimiter counts requests per key in a sliding window. Implementations must be safe for concurrent use.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error)
+}
+
+// slidingWindowScript keeps one sorted set member per request scored by its timestamp in
+// milliseconds. Trimming, counting and adding run atomically so concurrent requests cannot
+// overshoot the limit.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call("ZREMRANGEBYSCORE", key, 0, now - window)
+local count = redis.call("ZCARD", key)
+local allowed = 0
+if count < limit then
+	redis.call("ZADD", key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call("PEXPIRE", key, window)
+
+local oldest = redis.call("ZRANGE", key, 0, 0, "WITHSCORES")
+local reset = now + window
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window
+end
+return {allowed, count, reset}
+`)
+
+type RedisRateLimiter struct {
+	client *redis.Client
+}
+
+func NewRedisRateLimiter(client *redis.Client) *RedisRateLimiter {
+	return &RedisRateLimiter{client: client}
+}
+
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error) {
+	now := time.Now().UnixMilli()
+	values, err := slidingWindowScript.Run(ctx, l.client,
+		[]string{fmt.Sprintf("rate_limit:%s", key)},
+		now, window.Milliseconds(), limit, fmt.Sprintf("%d-%s", now, uuid.NewString()),
+	).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	return RateLimitResult{
+		Allowed:   values[0] == 1,
+		Limit:     limit,
+		Remaining: max(limit-int(values[1]), 0),
+		ResetAt:   time.UnixMilli(values[2]),
+	}, nil
+}
+
+// MemoryRateLimiter is a process local sliding window limiter for tests and for running without Redis
+type MemoryRateLimiter struct {
+	mu       sync.Mutex
+	requests map[string][]time.Time
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{requests: make(map[string][]time.Time)}
+}
+
+func (l *MemoryRateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-window)
+
+	timestamps := l.requests[key]
+	first := 0
+	for first < len(timestamps) && !timestamps[first].After(cutoff) {
+		first++
+	}
+	timestamps = timestamps[first:]
+
+	allowed := len(timestamps) < limit
+	if allowed {
+		timestamps = append(timestamps, now)
+	}
+
+	if len(timestamps) == 0 {
+		delete(l.requests, key)
+	} else {
+		l.requests[key] = timestamps
+	}
+
+	resetAt := now.Add(window)
+	if len(timestamps) > 0 {
+		resetAt = timestamps[0].Add(window)
+	}
+
+	return RateLimitResult{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: max(limit-len(timestamps), 0),
+		ResetAt:   resetAt,
+	}, nil
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// ReauthWindow is how long a successful re-authentication unlocks sensitive operations for a session
+const ReauthWindow = 5 * time.Minute
+
+func reauthKey(sessionID uuid.UUID) string {
+	return fmt.Sprintf("reauth:%s", sessionID)
+}
+
+// MarkSessionReauthenticated records a fresh re-authentication proof for the session and returns its expiry
+func MarkSessionReauthenticated(sessionID uuid.UUID, redisClient *redis.Client, ctx context.Context) (time.Time, error) {
+	expiresAt := time.Now().Add(ReauthWindow)
+	if err := redisClient.Set(ctx, reauthKey(sessionID), expiresAt.Unix(), ReauthWindow).Err(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to store re-authentication: %w", err)
+	}
+	return expiresAt, nil
+}
+
+// IsSessionReauthenticated reports whether the session re-authenticated within ReauthWindow
+func IsSessionReauthenticated(sessionID uuid.UUID, redisClient *redis.Client, ctx context.Context) (bool, error) {
+	exists, err := redisClient.Exists(ctx, reauthKey(sessionID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check re-authentication: %w", err)
+	}
+	return exists == 1, nil
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+const (
+	RecoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	// Lowercase letters and digits without the easily confused 0/o, 1/l/i
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// GenerateRecoveryCodes returns n random codes formatted as xxxxx-xxxxx
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	alphabetSize := big.NewInt(int64(len(recoveryCodeAlphabet)))
+
+	for i := 0; i < n; i++ {
+		var sb strings.Builder
+		for j := 0; j < recoveryCodeLength; j++ {
+			if j == recoveryCodeLength/2 {
+				sb.WriteByte('-')
+			}
+			idx, err := rand.Int(rand.Reader, alphabetSize)
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteByte(recoveryCodeAlphabet[idx.Int64()])
+		}
+		codes = append(codes, sb.String())
+	}
+
+	return codes, nil
+}
+
+// HashRecoveryCode normalises user input (case, spaces, dashes) before hashing it
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(code)
+	normalized = strings.ReplaceAll(normalized, "-", "")
+	normalized = strings.ReplaceAll(normalized, " ", "")
+	return HashToken(normalized)
+}
@@ -0,0 +1,130 @@
+package utils
+
+import "time"
+
+// Supported recurrence frequencies
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+	FrequencyYearly  = "yearly"
+)
+
+// Recurrence is an RRULE-like schedule: every Interval days, weeks, months or years from Start,
+// stopping after Until or after Count occurrences, whichever comes first
+type Recurrence struct {
+	Frequency string
+	Interval  int
+	Start     time.Time
+	Until     *time.Time
+	Count     *int
+}
+
+// Occurrence returns the n-th occurrence, counting from zero, and false when the schedule ends
+// before it. Each occurrence is computed from Start rather than from the previous one so that
+// months without the start day clamp to their last day without drifting the rest of the series.
+func (r Recurrence) Occurrence(n int) (time.Time, bool) {
+	if n < 0 || (r.Count != nil && n >= *r.Count) {
+		return time.Time{}, false
+	}
+
+	occurrence, ok := r.unbounded(n)
+	if !ok || (r.Until != nil && occurrence.After(*r.Until)) {
+		return time.Time{}, false
+	}
+	return occurrence, true
+}
+
+// IndexAfter returns the index of the first occurrence strictly after t. When there is none it
+// returns false with the index the series ends at, one past its last occurrence.
+func (r Recurrence) IndexAfter(t time.Time) (int, bool) {
+	n, ok := r.unboundedIndexAfter(t)
+	if !ok {
+		return 0, false
+	}
+	if _, ok := r.Occurrence(n); ok {
+		return n, true
+	}
+
+	end := n
+	if r.Until != nil {
+		end, _ = r.unboundedIndexAfter(*r.Until)
+	}
+	if r.Count != nil && *r.Count < end {
+		end = *r.Count
+	}
+	return end, false
+}
+
+// unbounded returns the n-th occurrence as if the schedule never ended, and false for an unknown frequency
+func (r Recurrence) unbounded(n int) (time.Time, bool) {
+	switch r.Frequency {
+	case FrequencyDaily:
+		return r.Start.AddDate(0, 0, n*r.interval()), true
+	case FrequencyWeekly:
+		return r.Start.AddDate(0, 0, 7*n*r.interval()), true
+	case FrequencyMonthly:
+		return addMonthsClamped(r.Start, n*r.interval()), true
+	case FrequencyYearly:
+		return addMonthsClamped(r.Start, 12*n*r.interval()), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// unboundedIndexAfter is IndexAfter for the schedule without its end. It estimates the index from
+// the time between Start and t, then corrects the estimate by the step or two that clamped month
+// ends and DST changes can put it off by.
+func (r Recurrence) unboundedIndexAfter(t time.Time) (int, bool) {
+	if _, ok := r.unbounded(0); !ok {
+		return 0, false
+	}
+
+	n := 0
+	if t.After(r.Start) {
+		local := t.In(r.Start.Location())
+		months := (local.Year()-r.Start.Year())*12 + int(local.Month()-r.Start.Month())
+		days := int(t.Sub(r.Start).Hours() / 24)
+		switch r.Frequency {
+		case FrequencyDaily:
+			n = days / r.interval()
+		case FrequencyWeekly:
+			n = days / (7 * r.interval())
+		case FrequencyMonthly:
+			n = months / r.interval()
+		case FrequencyYearly:
+			n = months / (12 * r.interval())
+		}
+	}
+
+	for n > 0 {
+		if previous, _ := r.unbounded(n - 1); !previous.After(t) {
+			break
+		}
+		n--
+	}
+	for {
+		if occurrence, _ := r.unbounded(n); occurrence.After(t) {
+			return n, true
+		}
+		n++
+	}
+}
+
+func (r Recurrence) interval() int {
+	if r.Interval < 1 {
+		return 1
+	}
+	return r.Interval
+}
+
+// addMonthsClamped moves t by months, keeping its day unless the target month is shorter
+func addMonthsClamped(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	target := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := target.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return target.AddDate(0, 0, day-1)
+}
@@ -10,7 +10,9 @@ import (
 	"github.com/google/uuid"
 )
 
-func CreateSessionAndRefreshToken(user *models.User, ip, agent string, sessionDatabaseService database.SessionDatabaseServiceInterface, refreshTokenDatabaseService database.RefreshTokenDatabaseServiceInterface, c *gin.Context) (models.Session, models.RefreshToken, error) {
+// CreateSessionAndRefreshToken starts a new session. When parent is nil the refresh token
+// begins a new rotation family, otherwise it joins the family of the token it replaces.
+func CreateSessionAndRefreshToken(user *models.User, ip, agent string, parent *models.RefreshToken, sessionDatabaseService database.SessionDatabaseServiceInterface, refreshTokenDatabaseService database.RefreshTokenDatabaseServiceInterface, c *gin.Context) (models.Session, models.RefreshToken, error) {
 
 	sessionToken := uuid.New()
 	refreshToken := uuid.New()
@@ -20,7 +22,7 @@ func CreateSessionAndRefreshToken(user *models.User, ip, agent string, sessionDa
 		ID:        uuid.New(),
 		UserID:    user.ID,
 		CreatedAt: now,
-		ExpiresAt: now.Add(15 * time.Minute),
+		ExpiresAt: now.Add(AccessTokenTTL),
 		IPAddress: ip,
 		UserAgent: agent,
 		Token:     sessionToken,
@@ -36,6 +38,13 @@ func CreateSessionAndRefreshToken(user *models.User, ip, agent string, sessionDa
 		Revoked:   false,
 	}
 
+	if parent != nil {
+		refresh.FamilyID = parent.FamilyID
+		refresh.ParentID = &parent.ID
+	} else {
+		refresh.FamilyID = refresh.ID
+	}
+
 	if err := sessionDatabaseService.CreateSession(&session); err != nil {
 		return models.Session{}, models.RefreshToken{}, errors.New("failed to create session")
 	}
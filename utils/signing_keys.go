@@ -0,0 +1,403 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlsoShantanuBorkar/budget_max/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Supported values for JWT_ALGORITHM
+const (
+	SigningAlgorithmHS256 = "HS256"
+	SigningAlgorithmRS256 = "RS256"
+	SigningAlgorithmEdDSA = "EdDSA"
+)
+
+const rsaKeyBits = 2048
+
+// JWKSMaxAge is how long clients may cache the JWKS. A new key is published for this long before
+// it signs anything, so every cached set already contains it.
+const JWKSMaxAge = 5 * time.Minute
+
+// signingKeyReloadInterval is how often the keys directory is re-read for keys generated by other
+// instances. It has to be shorter than JWKSMaxAge so they are known here before they start signing.
+const signingKeyReloadInterval = time.Minute
+
+// SigningKey is one key of the ring. Only asymmetric keys are published in the JWKS.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	CreatedAt time.Time
+	private   any
+	public    any
+}
+
+// JWK is the public part of a signing key in RFC 7517 format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// SigningKeyRing holds every key tokens may still be verified with and the one new tokens are signed with
+type SigningKeyRing struct {
+	mu        sync.RWMutex
+	keys      map[string]*SigningKey
+	algorithm string
+	keysDir   string
+	interval  time.Duration
+}
+
+var signingKeys *SigningKeyRing
+
+// InitSigningKeys builds the key ring for JWT_ALGORITHM. HS256 uses JWT_SECRET under JWT_KEY_ID.
+// RS256 and EdDSA load every <kid>.pem private key from JWT_KEYS_DIR, generating one when the
+// directory is empty, and sign with the newest one that has been published for JWKSMaxAge.
+func InitSigningKeys(config *config.AppConfig) error {
+	ring := &SigningKeyRing{
+		keys:      make(map[string]*SigningKey),
+		algorithm: config.JWTAlgorithm,
+		keysDir:   config.JWTKeysDir,
+		interval:  config.JWTKeyRotationInterval,
+	}
+
+	switch config.JWTAlgorithm {
+	case SigningAlgorithmHS256:
+		ring.add(&SigningKey{
+			ID:        config.JWTKeyID,
+			Algorithm: SigningAlgorithmHS256,
+			CreatedAt: time.Now(),
+			private:   []byte(config.JWTSecret),
+			public:    []byte(config.JWTSecret),
+		})
+	case SigningAlgorithmRS256, SigningAlgorithmEdDSA:
+		if err := ring.load(); err != nil {
+			return err
+		}
+		if len(ring.keys) == 0 {
+			if err := ring.Rotate(); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported JWT_ALGORITHM %q", config.JWTAlgorithm)
+	}
+
+	signingKeys = ring
+	return nil
+}
+
+// GetSigningKeys returns the key ring set up by InitSigningKeys
+func GetSigningKeys() *SigningKeyRing {
+	return signingKeys
+}
+
+// StartSigningKeyRotation generates a new signing key every JWT_KEY_ROTATION_INTERVAL. Instances
+// sharing JWT_KEYS_DIR pick up each other's keys, and only rotate when nobody has within the
+// interval. Retired keys keep verifying tokens for one more interval before they are dropped.
+func StartSigningKeyRotation() {
+	ring := GetSigningKeys()
+	if ring == nil || ring.interval <= 0 || ring.algorithm == SigningAlgorithmHS256 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(signingKeyReloadInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := ring.load(); err != nil {
+				GetLogger().Error().Err(err).Msg("Failed to reload JWT signing keys")
+				continue
+			}
+			if ring.rotationDue() {
+				if err := ring.Rotate(); err != nil {
+					GetLogger().Error().Err(err).Msg("Failed to rotate JWT signing key")
+					continue
+				}
+			}
+			if err := ring.prune(); err != nil {
+				GetLogger().Error().Err(err).Msg("Failed to prune retired JWT signing keys")
+			}
+		}
+	}()
+}
+
+// Current returns the key new tokens are signed with
+func (r *SigningKeyRing) Current() *SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current()
+}
+
+// Lookup returns the key with the given kid if it is still part of the ring
+func (r *SigningKeyRing) Lookup(kid string) (*SigningKey, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok := r.keys[kid]
+	return key, ok
+}
+
+// Rotate generates a new key pair and persists it. It is published in the JWKS right away and
+// becomes the signing key once it is older than JWKSMaxAge.
+func (r *SigningKeyRing) Rotate() error {
+	key, err := generateSigningKey(r.algorithm)
+	if err != nil {
+		return err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key.private)
+	if err != nil {
+		return fmt.Errorf("failed to encode signing key: %w", err)
+	}
+
+	if err := os.MkdirAll(r.keysDir, 0700); err != nil {
+		return fmt.Errorf("failed to create keys directory: %w", err)
+	}
+
+	path := filepath.Join(r.keysDir, key.ID+".pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return fmt.Errorf("failed to write signing key: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.add(key)
+	return nil
+}
+
+// JWKS returns the public keys of the ring, newest first
+func (r *SigningKeyRing) JWKS() JWKSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range r.sortedKeys() {
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				N:         base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	return set
+}
+
+// add registers the key. Callers hold the write lock.
+func (r *SigningKeyRing) add(key *SigningKey) {
+	r.keys[key.ID] = key
+}
+
+// current picks the newest key published for at least JWKSMaxAge, or the oldest key when none has
+// been yet, as on a fresh install. Callers hold the lock.
+func (r *SigningKeyRing) current() *SigningKey {
+	keys := r.sortedKeys()
+	if len(keys) == 0 {
+		return nil
+	}
+
+	published := time.Now().Add(-JWKSMaxAge)
+	for _, key := range keys {
+		if !key.CreatedAt.After(published) {
+			return key
+		}
+	}
+	return keys[len(keys)-1]
+}
+
+// rotationDue reports whether the newest key, possibly generated by another instance, is older
+// than the rotation interval
+func (r *SigningKeyRing) rotationDue() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := r.sortedKeys()
+	return len(keys) == 0 || time.Since(keys[0].CreatedAt) >= r.interval
+}
+
+func (r *SigningKeyRing) sortedKeys() []*SigningKey {
+	keys := make([]*SigningKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys
+}
+
+// prune drops keys that stopped signing more than one rotation interval ago. A key signs from
+// JWKSMaxAge after it was created until its successor does, one interval later.
+func (r *SigningKeyRing) prune() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.current()
+	cutoff := time.Now().Add(-2*r.interval - JWKSMaxAge)
+	for id, key := range r.keys {
+		if key == current || key.CreatedAt.After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(r.keysDir, id+".pem")); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove signing key %s: %w", id, err)
+		}
+		delete(r.keys, id)
+	}
+	return nil
+}
+
+// load reads every PEM encoded private key of the configured algorithm from the keys directory
+// that is not part of the ring yet
+func (r *SigningKeyRing) load() error {
+	paths, err := filepath.Glob(filepath.Join(r.keysDir, "*.pem"))
+	if err != nil {
+		return fmt.Errorf("failed to list signing keys: %w", err)
+	}
+
+	for _, path := range paths {
+		if _, ok := r.Lookup(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))); ok {
+			continue
+		}
+		key, err := readSigningKey(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue // pruned by another instance since the listing
+		}
+		if err != nil {
+			return err
+		}
+		if key.Algorithm != r.algorithm {
+			continue
+		}
+		r.mu.Lock()
+		r.add(key)
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+func readSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %s: %w", path, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat signing key %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", path)
+	}
+
+	var private any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+
+	key := &SigningKey{
+		ID:        strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		CreatedAt: info.ModTime(),
+		private:   private,
+	}
+
+	switch private := private.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm = SigningAlgorithmRS256
+		key.public = &private.PublicKey
+	case ed25519.PrivateKey:
+		key.Algorithm = SigningAlgorithmEdDSA
+		key.public = private.Public()
+	default:
+		return nil, fmt.Errorf("signing key %s has an unsupported key type", path)
+	}
+
+	return key, nil
+}
+
+func generateSigningKey(algorithm string) (*SigningKey, error) {
+	key := &SigningKey{
+		ID:        uuid.NewString(),
+		Algorithm: algorithm,
+		CreatedAt: time.Now(),
+	}
+
+	switch algorithm {
+	case SigningAlgorithmRS256:
+		private, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+		}
+		key.private = private
+		key.public = &private.PublicKey
+	case SigningAlgorithmEdDSA:
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate Ed25519 key: %w", err)
+		}
+		key.private = private
+		key.public = public
+	default:
+		return nil, fmt.Errorf("cannot generate keys for %q", algorithm)
+	}
+
+	return key, nil
+}
+
+func (k *SigningKey) signingMethod() jwt.SigningMethod {
+	switch k.Algorithm {
+	case SigningAlgorithmRS256:
+		return jwt.SigningMethodRS256
+	case SigningAlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}